@@ -0,0 +1,140 @@
+package objex
+
+import (
+	"context"
+	"io"
+	"iter"
+)
+
+// BucketHandle works with the objects of one bucket. Unlike SetBucket,
+// which changes the bucket every later call on a Store uses, a handle is
+// bound to its bucket for good, so one Store can serve many buckets from
+// many goroutines at once. Object names are keys within the bucket.
+type BucketHandle interface {
+	// Name returns the bucket the handle is bound to.
+	Name() string
+	CreateObject(ctx context.Context, key string, data io.Reader, contentType string) error
+	PutObject(ctx context.Context, key string, data io.Reader, opts PutOptions) error
+	ReadObject(ctx context.Context, key string) ([]byte, error)
+	OpenObject(ctx context.Context, key string, opts GetOptions) (io.ReadCloser, *ObjectMetaData, error)
+	UpdateObject(ctx context.Context, key string, data io.Reader) error
+	DeleteObject(ctx context.Context, key string) error
+	DeleteObjectIf(ctx context.Context, key string, cond Conditions) error
+	ListObjects(ctx context.Context) ([]*ObjectMetaData, error)
+	ListObjectsPage(ctx context.Context, opts ListOptions) (*ListResult, error)
+	Objects(ctx context.Context, opts ListOptions) iter.Seq2[*ObjectMetaData, error]
+	Exists(ctx context.Context, key string) (bool, *ObjectMetaData, error)
+	Metadata(ctx context.Context, key string) (*ObjectMetaData, error)
+	// CopyObject, CopyObjectIf and MoveObject work within the bucket.
+	CopyObject(ctx context.Context, srcKey, destKey string) error
+	CopyObjectIf(ctx context.Context, srcKey, destKey string, cond CopyConditions) error
+	MoveObject(ctx context.Context, srcKey, destKey string) error
+}
+
+// NewBucketHandle returns a BucketHandle for bucket backed by store, whose
+// current bucket must already be bucket and must never change. Drivers
+// implement Bucket by passing it a copy of themselves bound to the bucket.
+func NewBucketHandle(bucket string, store StoreContext) BucketHandle {
+	return bucketHandle{name: bucket, store: store}
+}
+
+// WrapBucket returns handle with the store behind it wrapped by
+// middleware, so middleware that overrides Bucket applies to the handles it
+// returns too. Handles not made by NewBucketHandle are returned unchanged.
+func WrapBucket(handle BucketHandle, middleware Middleware) BucketHandle {
+	h, ok := handle.(bucketHandle)
+	if !ok {
+		return handle
+	}
+	return bucketHandle{name: h.name, store: middleware(Adapt(h.store))}
+}
+
+// BucketReporter is implemented by stores that report their current
+// bucket, the one object names without a bucket resolve to.
+type BucketReporter interface {
+	CurrentBucket() string
+}
+
+// CurrentBucket returns the current bucket of the store behind store,
+// looking through wrappers such as the one returned by Adapt, or "" when it
+// has none or does not implement BucketReporter.
+func CurrentBucket(store StoreContext) string {
+	if reporter, ok := as[BucketReporter](store); ok {
+		return reporter.CurrentBucket()
+	}
+	return ""
+}
+
+type bucketHandle struct {
+	name  string
+	store StoreContext
+}
+
+// Unwrap returns the bound store.
+func (h bucketHandle) Unwrap() StoreContext {
+	return h.store
+}
+
+func (h bucketHandle) Name() string {
+	return h.name
+}
+
+func (h bucketHandle) CreateObject(ctx context.Context, key string, data io.Reader, contentType string) error {
+	return h.store.CreateObjectContext(ctx, key, data, contentType)
+}
+
+func (h bucketHandle) PutObject(ctx context.Context, key string, data io.Reader, opts PutOptions) error {
+	return h.store.PutObject(ctx, key, data, opts)
+}
+
+func (h bucketHandle) ReadObject(ctx context.Context, key string) ([]byte, error) {
+	return h.store.ReadObjectContext(ctx, key)
+}
+
+func (h bucketHandle) OpenObject(ctx context.Context, key string, opts GetOptions) (io.ReadCloser, *ObjectMetaData, error) {
+	return h.store.OpenObject(ctx, key, opts)
+}
+
+func (h bucketHandle) UpdateObject(ctx context.Context, key string, data io.Reader) error {
+	return h.store.UpdateObjectContext(ctx, key, data)
+}
+
+func (h bucketHandle) DeleteObject(ctx context.Context, key string) error {
+	return h.store.DeleteObjectContext(ctx, key)
+}
+
+func (h bucketHandle) DeleteObjectIf(ctx context.Context, key string, cond Conditions) error {
+	return h.store.DeleteObjectIf(ctx, key, cond)
+}
+
+func (h bucketHandle) ListObjects(ctx context.Context) ([]*ObjectMetaData, error) {
+	return h.store.ListObjectsContext(ctx, h.name)
+}
+
+func (h bucketHandle) ListObjectsPage(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	return h.store.ListObjectsPage(ctx, h.name, opts)
+}
+
+func (h bucketHandle) Objects(ctx context.Context, opts ListOptions) iter.Seq2[*ObjectMetaData, error] {
+	return h.store.Objects(ctx, h.name, opts)
+}
+
+func (h bucketHandle) Exists(ctx context.Context, key string) (bool, *ObjectMetaData, error) {
+	return h.store.ExistsContext(ctx, key)
+}
+
+func (h bucketHandle) Metadata(ctx context.Context, key string) (*ObjectMetaData, error) {
+	return h.store.MetadataContext(ctx, key)
+}
+
+func (h bucketHandle) CopyObject(ctx context.Context, srcKey, destKey string) error {
+	return h.store.CopyObjectContext(ctx, srcKey, destKey)
+}
+
+func (h bucketHandle) CopyObjectIf(ctx context.Context, srcKey, destKey string, cond CopyConditions) error {
+	return h.store.CopyObjectIf(ctx, srcKey, destKey, cond)
+}
+
+func (h bucketHandle) MoveObject(ctx context.Context, srcKey, destKey string) error {
+	return h.store.MoveObjectContext(ctx, srcKey, destKey)
+}
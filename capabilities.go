@@ -0,0 +1,47 @@
+package objex
+
+// Capabilities lists the features a store supports beyond the Store
+// interface, so callers can pick another approach up front instead of
+// failing with ErrNotSupported at runtime.
+type Capabilities struct {
+	// Presign reports that PresignObject can hand out URLs. A store may
+	// implement Presigner and still lack the configuration to sign.
+	Presign bool
+	// Multipart reports that the store implements MultipartUploader.
+	Multipart bool
+	// ServerSideCopy reports that CopyObject and MoveObject happen in the
+	// backend, without streaming the object through the client.
+	ServerSideCopy bool
+	// AllConditions reports that writes and deletes accept every field of
+	// Conditions, not only the IfMatch and IfNoneMatch S3 takes on writes
+	// and IfMatch on deletes.
+	AllConditions bool
+	// Versioning reports that the store implements Versioner.
+	Versioning bool
+	// ObjectLock reports that objects can be protected from being deleted
+	// or overwritten for a retention period through objex. No driver
+	// reports it yet, as objex has no object lock API.
+	ObjectLock bool
+}
+
+// CapabilityReporter is implemented by stores that report their
+// Capabilities.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the Capabilities of the store behind store, looking
+// through wrappers such as the one returned by Adapt. Stores that do not
+// implement CapabilityReporter are assumed to support presigning, multipart
+// uploads and versioning when they implement Presigner, MultipartUploader
+// and Versioner, and nothing else.
+func CapabilitiesOf(store StoreContext) Capabilities {
+	if reporter, ok := as[CapabilityReporter](store); ok {
+		return reporter.Capabilities()
+	}
+
+	_, presign := AsPresigner(store)
+	_, multipart := AsMultipartUploader(store)
+	_, versioning := AsVersioner(store)
+	return Capabilities{Presign: presign, Multipart: multipart, Versioning: versioning}
+}
@@ -0,0 +1,51 @@
+package objex_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+)
+
+// bareStore hides every method of the store it embeds but those of
+// StoreContext, Capabilities included.
+type bareStore struct {
+	objex.StoreContext
+}
+
+// barePresigner is a bareStore that can presign.
+type barePresigner struct {
+	bareStore
+}
+
+func (barePresigner) PresignObject(context.Context, string, string, time.Duration) (string, error) {
+	return "https://example.com/signed", nil
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	mem, err := memory.NewStore(memory.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		store objex.StoreContext
+		want  objex.Capabilities
+	}{
+		{"Reporter", mem, mem.Capabilities()},
+		// The reporter is found through middleware.
+		{"Middleware", objex.Chain(mem, KeepBuckets, objex.Retry(objex.RetryOptions{})), mem.Capabilities()},
+		// Without a reporter, only the optional interfaces count.
+		{"Presigner", barePresigner{bareStore{mem}}, objex.Capabilities{Presign: true}},
+		{"Neither", bareStore{mem}, objex.Capabilities{}},
+	}
+
+	for _, test := range tests {
+		if got := objex.CapabilitiesOf(test.store); got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package objex
+
+import (
+	"strings"
+	"time"
+)
+
+// Conditions are HTTP-style preconditions on the current state of an
+// object. An operation with conditions that do not hold fails with
+// ErrPreconditionFailed and leaves the object untouched. The zero value has
+// no conditions.
+//
+// S3 accepts every condition on reads and on the source of a copy, but only
+// IfMatch and IfNoneMatch on writes and IfMatch on deletes; the aws and
+// minio drivers return ErrNotSupported for the others. The filesystem driver
+// supports every condition everywhere.
+type Conditions struct {
+	// IfMatch requires the object to exist with this ETag. "*" only
+	// requires the object to exist.
+	IfMatch string
+	// IfNoneMatch requires the object's ETag to differ from this one. "*"
+	// requires the object not to exist, which makes a write create-only.
+	IfNoneMatch string
+	// IfModifiedSince requires the object to have changed after this time.
+	IfModifiedSince time.Time
+	// IfUnmodifiedSince requires the object not to have changed after this
+	// time.
+	IfUnmodifiedSince time.Time
+}
+
+// CopyConditions are the preconditions of a copy. Source is checked against
+// the object being copied and Destination against the object it replaces.
+type CopyConditions struct {
+	Source      Conditions
+	Destination Conditions
+}
+
+// IsZero reports whether c has no conditions.
+func (c Conditions) IsZero() bool {
+	return c.IfMatch == "" && c.IfNoneMatch == "" && c.IfModifiedSince.IsZero() && c.IfUnmodifiedSince.IsZero()
+}
+
+// HasDates reports whether c uses IfModifiedSince or IfUnmodifiedSince.
+func (c Conditions) HasDates() bool {
+	return !c.IfModifiedSince.IsZero() || !c.IfUnmodifiedSince.IsZero()
+}
+
+// Check reports ErrPreconditionFailed when c does not hold for meta, the
+// current state of the object, or nil if it does not exist. Drivers that
+// cannot send conditions to their backend use it to emulate them.
+func (c Conditions) Check(meta *ObjectMetaData) error {
+	if meta == nil {
+		if c.IfMatch != "" {
+			return ErrPreconditionFailed
+		}
+		return nil
+	}
+
+	if c.IfMatch != "" && !matchETag(c.IfMatch, meta.ETag) {
+		return ErrPreconditionFailed
+	}
+	if c.IfNoneMatch != "" && matchETag(c.IfNoneMatch, meta.ETag) {
+		return ErrPreconditionFailed
+	}
+
+	if !c.HasDates() {
+		return nil
+	}
+
+	modified, err := parseLastModified(meta.LastModified)
+	if err != nil {
+		return err
+	}
+	if !c.IfModifiedSince.IsZero() && !modified.After(c.IfModifiedSince.Truncate(time.Second)) {
+		return ErrPreconditionFailed
+	}
+	if !c.IfUnmodifiedSince.IsZero() && modified.After(c.IfUnmodifiedSince.Truncate(time.Second)) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+func matchETag(condition, etag string) bool {
+	if condition == "*" {
+		return true
+	}
+	return strings.Trim(condition, `"`) == strings.Trim(etag, `"`)
+}
+
+// parseLastModified parses ObjectMetaData.LastModified, which is RFC 3339
+// in most drivers and time.Time.String in minio, to whole seconds like the
+// HTTP date headers the conditions are compared with.
+func parseLastModified(value string) (time.Time, error) {
+	modified, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		modified, err = time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", value)
+	}
+	return modified.Truncate(time.Second), err
+}
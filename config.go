@@ -0,0 +1,301 @@
+package objex
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by driver Configs that can check their required
+// fields before a store is built. New calls it.
+type Validator interface {
+	Validate() error
+}
+
+var configs = make(map[string]func(settings map[string]any) (NamedConfig, error))
+
+// RegisterConfig lets LoadStores and LoadStoresFromEnv build the driver
+// Config C from settings keyed by its json tags. Drivers call it from init
+// next to Register.
+func RegisterConfig[C NamedConfig]() {
+	var zero C
+	configs[zero.DriverName()] = func(settings map[string]any) (NamedConfig, error) {
+		var config C
+		err := decodeSettings(&config, settings)
+		return config, err
+	}
+}
+
+// LoadStores builds the stores described by a config document. unmarshal
+// decodes data, e.g. json.Unmarshal or yaml.Unmarshal, and the document
+// names each store under "stores":
+//
+//	stores:
+//	  uploads:
+//	    driver: aws
+//	    region: us-east-1
+//	    bucket: uploads
+//	    access_key: AKIA...
+//	    secret_key: ...
+//	  thumbnails:
+//	    url: mem://thumbnails
+//
+// Each store has either a driver, whose Config fields are set from the
+// other keys by their json tags, or a url as taken by Open. opts apply to
+// every store. If any store cannot be built, the ones built before it are
+// cleaned up and no stores are returned.
+func LoadStores(data []byte, unmarshal func(data []byte, v any) error, opts ...Option) (map[string]Store, error) {
+	var document struct {
+		Stores map[string]map[string]any `json:"stores" yaml:"stores"`
+	}
+	err := unmarshal(data, &document)
+	if err != nil {
+		return nil, &Error{Kind: ErrClientInit, Op: "LoadStores", Err: err}
+	}
+
+	return newStores(document.Stores, opts)
+}
+
+// LoadStoresFromEnv builds stores from environment variables starting with
+// prefix. A store is declared by PREFIX<NAME>_DRIVER or PREFIX<NAME>_URL and
+// configured by PREFIX<NAME>_<FIELD>, where FIELD is the upper case json
+// tag of a Config field:
+//
+//	OBJEX_UPLOADS_DRIVER=aws
+//	OBJEX_UPLOADS_BUCKET=uploads
+//	OBJEX_UPLOADS_ACCESS_KEY=AKIA...
+//	OBJEX_THUMBNAILS_URL=mem://thumbnails
+//
+// Store names are lower case, so these declare "uploads" and "thumbnails".
+// A store name cannot extend another with an underscore: with
+// OBJEX_FILES_DRIVER set, OBJEX_FILES_PRESIGN_BASE_URL is a field of
+// "files", not a store of its own. Failures are handled as by LoadStores.
+func LoadStoresFromEnv(prefix string, opts ...Option) (map[string]Store, error) {
+	env := make(map[string]string)
+	var names []string
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		key, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		env[key] = value
+
+		for _, suffix := range []string{"_DRIVER", "_URL"} {
+			if name, ok := strings.CutSuffix(key, suffix); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+	names = slices.Compact(names)
+	// A name that extends another with an underscore is one of its fields,
+	// e.g. FILES_PRESIGN_BASE_URL belongs to FILES.
+	declared := names
+	names = nil
+	for _, name := range declared {
+		nested := slices.ContainsFunc(declared, func(owner string) bool {
+			return strings.HasPrefix(name, owner+"_")
+		})
+		if !nested {
+			names = append(names, name)
+		}
+	}
+
+	stores := make(map[string]map[string]any)
+	for _, name := range names {
+		settings := make(map[string]any)
+		for key, value := range env {
+			field, ok := strings.CutPrefix(key, name+"_")
+			if !ok {
+				continue
+			}
+			settings[strings.ToLower(field)] = value
+			delete(env, key)
+		}
+		stores[strings.ToLower(name)] = settings
+	}
+
+	return newStores(stores, opts)
+}
+
+// newStores builds stores in name order. When one fails, the stores built
+// before it are cleaned up; their own errors are dropped in favour of the
+// one that stopped the load.
+func newStores(settings map[string]map[string]any, opts []Option) (map[string]Store, error) {
+	stores := make(map[string]Store, len(settings))
+	for _, name := range slices.Sorted(maps.Keys(settings)) {
+		store, err := newStore(settings[name], opts)
+		if err != nil {
+			for _, store := range stores {
+				store.CleanUp()
+			}
+			return nil, configError(name, err)
+		}
+		stores[name] = store
+	}
+	return stores, nil
+}
+
+func newStore(settings map[string]any, opts []Option) (Store, error) {
+	rawURL, hasURL := settings["url"]
+	driverName, hasDriver := settings["driver"]
+	if hasURL == hasDriver {
+		return nil, errors.New("set either driver or url")
+	}
+
+	if hasURL {
+		if len(settings) > 1 {
+			return nil, errors.New("url cannot be combined with other settings")
+		}
+		rawURL, ok := rawURL.(string)
+		if !ok {
+			return nil, errors.New("url must be a string")
+		}
+		return Open(rawURL, opts...)
+	}
+
+	name, ok := driverName.(string)
+	if !ok {
+		return nil, errors.New("driver must be a string")
+	}
+	newConfig, ok := configs[name]
+	if !ok {
+		return nil, ErrUnknownDriver
+	}
+
+	fields := make(map[string]any, len(settings)-1)
+	for key, value := range settings {
+		if key != "driver" {
+			fields[key] = value
+		}
+	}
+
+	config, err := newConfig(fields)
+	if err != nil {
+		return nil, err
+	}
+	return New(config, opts...)
+}
+
+// configError describes a store that could not be loaded, keeping the kind
+// of err.
+func configError(name string, err error) error {
+	kind := KindOf(err)
+	if kind == nil {
+		kind = ErrClientInit
+	}
+	return &Error{Kind: kind, Op: "LoadStores", Err: &storeConfigError{name: name, err: err}}
+}
+
+// storeConfigError names the store whose settings caused err.
+type storeConfigError struct {
+	name string
+	err  error
+}
+
+func (e *storeConfigError) Error() string {
+	msg := "store " + strconv.Quote(e.name)
+	if KindOf(e.err) != e.err {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *storeConfigError) Unwrap() error {
+	return e.err
+}
+
+// decodeSettings sets the fields of the struct config points to from
+// settings keyed by their json tags. Strings are converted to the field's
+// type, so values read from the environment work too.
+func decodeSettings(config any, settings map[string]any) error {
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+
+	fields := make(map[string]reflect.Value)
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && t.Field(i).IsExported() {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	for key, value := range settings {
+		field, ok := fields[key]
+		if !ok {
+			return errors.New("unknown setting " + key)
+		}
+		err := setField(field, value)
+		if err != nil {
+			return errors.New("invalid " + key + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value any) error {
+	s, isString := value.(string)
+
+	switch field.Kind() {
+	case reflect.String:
+		switch value := value.(type) {
+		case string:
+			field.SetString(value)
+		case bool:
+			field.SetString(strconv.FormatBool(value))
+		case int:
+			field.SetString(strconv.Itoa(value))
+		case float64:
+			field.SetString(strconv.FormatFloat(value, 'f', -1, 64))
+		default:
+			return errors.New("want a string")
+		}
+
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if isString {
+			var err error
+			b, err = strconv.ParseBool(s)
+			ok = err == nil
+		}
+		if !ok {
+			return errors.New("want true or false")
+		}
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch value := value.(type) {
+		case int:
+			n = int64(value)
+		case float64:
+			if value != math.Trunc(value) {
+				return errors.New("want an integer")
+			}
+			n = int64(value)
+		case string:
+			var err error
+			n, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.New("want an integer")
+			}
+		default:
+			return errors.New("want an integer")
+		}
+		if field.OverflowInt(n) {
+			return errors.New("out of range")
+		}
+		field.SetInt(n)
+
+	default:
+		return errors.New("cannot be set from a config")
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package objex_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+)
+
+// configTestConfig is the Config of the "configtest" driver, which records
+// the configs it is given and the stores it cleans up.
+type configTestConfig struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Enabled bool   `json:"enabled"`
+	Fail    bool   `json:"fail"`
+}
+
+func (configTestConfig) DriverName() string {
+	return "configtest"
+}
+
+var (
+	configured []configTestConfig
+	cleaned    []string
+)
+
+type configTestStore struct {
+	objex.Passthrough
+	name string
+}
+
+func (s *configTestStore) CleanUpContext(context.Context) error {
+	cleaned = append(cleaned, s.name)
+	return nil
+}
+
+func init() {
+	objex.Register("configtest", func(config any) (objex.Store, error) {
+		conf := config.(configTestConfig)
+		configured = append(configured, conf)
+		if conf.Fail {
+			return nil, objex.ErrClientInit
+		}
+
+		mem, err := memory.NewStore(memory.Config{})
+		if err != nil {
+			return nil, err
+		}
+		return objex.Adapt(&configTestStore{Passthrough: objex.Passthrough{StoreContext: mem}, name: conf.Name}), nil
+	})
+	objex.RegisterConfig[configTestConfig]()
+}
+
+func resetConfigTest(t *testing.T) {
+	configured, cleaned = nil, nil
+	t.Cleanup(func() { configured, cleaned = nil, nil })
+}
+
+func TestLoadStores(t *testing.T) {
+	resetConfigTest(t)
+
+	stores, err := objex.LoadStores([]byte(`{"stores": {
+		"uploads": {"driver": "configtest", "name": "uploads", "count": 3, "enabled": true},
+		"thumbnails": {"url": "mem://thumbnails"}
+	}}`), json.Unmarshal)
+	if err != nil {
+		t.Fatalf("LoadStores: %v", err)
+	}
+
+	want := []configTestConfig{{Name: "uploads", Count: 3, Enabled: true}}
+	if !slices.Equal(configured, want) {
+		t.Errorf("LoadStores: got configs %+v, want %+v", configured, want)
+	}
+	if len(stores) != 2 || stores["uploads"] == nil || stores["thumbnails"] == nil {
+		t.Errorf("LoadStores: got stores %v, want uploads and thumbnails", stores)
+	}
+	if bucket := objex.CurrentBucket(stores["thumbnails"]); bucket != "thumbnails" {
+		t.Errorf("LoadStores: got thumbnails bucket %q, want %q", bucket, "thumbnails")
+	}
+}
+
+func TestLoadStoresErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stores string
+		want   error
+	}{
+		{"DriverAndURL", `{"a": {"driver": "configtest", "url": "mem://a"}}`, objex.ErrClientInit},
+		{"NeitherDriverNorURL", `{"a": {"name": "a"}}`, objex.ErrClientInit},
+		{"URLWithSettings", `{"a": {"url": "mem://a", "name": "a"}}`, objex.ErrClientInit},
+		{"UnknownDriver", `{"a": {"driver": "nope"}}`, objex.ErrUnknownDriver},
+		{"UnknownSetting", `{"a": {"driver": "configtest", "colour": "red"}}`, objex.ErrClientInit},
+		{"InvalidSetting", `{"a": {"driver": "configtest", "count": "three"}}`, objex.ErrClientInit},
+		{"UnknownScheme", `{"a": {"url": "nope://a"}}`, objex.ErrUnknownDriver},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetConfigTest(t)
+
+			stores, err := objex.LoadStores([]byte(`{"stores": `+test.stores+`}`), json.Unmarshal)
+			wantErr(t, "LoadStores", err, test.want)
+			if stores != nil {
+				t.Errorf("LoadStores: got stores %v, want none", stores)
+			}
+		})
+	}
+}
+
+func TestLoadStoresCleansUp(t *testing.T) {
+	resetConfigTest(t)
+
+	_, err := objex.LoadStores([]byte(`{"stores": {
+		"a": {"driver": "configtest", "name": "a"},
+		"b": {"driver": "configtest", "name": "b", "fail": true},
+		"c": {"driver": "configtest", "name": "c"}
+	}}`), json.Unmarshal)
+	wantErr(t, "LoadStores", err, objex.ErrClientInit)
+
+	// Stores are built in name order, so only a was built before b failed.
+	if !slices.Equal(cleaned, []string{"a"}) {
+		t.Errorf("LoadStores: cleaned up %q, want %q", cleaned, []string{"a"})
+	}
+}
+
+func TestLoadStoresFromEnv(t *testing.T) {
+	resetConfigTest(t)
+
+	t.Setenv("OBJEXTEST_UPLOADS_DRIVER", "configtest")
+	t.Setenv("OBJEXTEST_UPLOADS_NAME", "uploads")
+	t.Setenv("OBJEXTEST_UPLOADS_COUNT", "3")
+	t.Setenv("OBJEXTEST_UPLOADS_ENABLED", "true")
+	t.Setenv("OBJEXTEST_THUMBNAILS_URL", "mem://thumbnails")
+
+	stores, err := objex.LoadStoresFromEnv("OBJEXTEST_")
+	if err != nil {
+		t.Fatalf("LoadStoresFromEnv: %v", err)
+	}
+
+	want := []configTestConfig{{Name: "uploads", Count: 3, Enabled: true}}
+	if !slices.Equal(configured, want) {
+		t.Errorf("LoadStoresFromEnv: got configs %+v, want %+v", configured, want)
+	}
+	if len(stores) != 2 || stores["uploads"] == nil || stores["thumbnails"] == nil {
+		t.Errorf("LoadStoresFromEnv: got stores %v, want uploads and thumbnails", stores)
+	}
+}
+
+func TestLoadStoresFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want error
+	}{
+		{"DriverAndURL", map[string]string{
+			"OBJEXTEST_A_DRIVER": "configtest",
+			"OBJEXTEST_A_URL":    "mem://a",
+		}, objex.ErrClientInit},
+		{"UnknownDriver", map[string]string{
+			"OBJEXTEST_A_DRIVER": "nope",
+		}, objex.ErrUnknownDriver},
+		{"InvalidSetting", map[string]string{
+			"OBJEXTEST_A_DRIVER":  "configtest",
+			"OBJEXTEST_A_ENABLED": "maybe",
+		}, objex.ErrClientInit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetConfigTest(t)
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := objex.LoadStoresFromEnv("OBJEXTEST_")
+			wantErr(t, "LoadStoresFromEnv", err, test.want)
+
+			var configErr *objex.Error
+			if !errors.As(err, &configErr) || configErr.Op != "LoadStores" {
+				t.Errorf("LoadStoresFromEnv: got %#v, want a LoadStores *objex.Error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package objex
+
+import (
+	"context"
+	"io"
+)
+
+// Adapt returns a Store backed by a StoreContext. The context-free methods
+// run with context.Background(), so code written against the original Store
+// methods keeps working with implementations that only provide the
+// context-aware ones.
+func Adapt(store StoreContext) Store {
+	if s, ok := store.(Store); ok {
+		return s
+	}
+	return adapter{store}
+}
+
+type adapter struct {
+	StoreContext
+}
+
+// Unwrap returns the adapted store.
+func (a adapter) Unwrap() StoreContext {
+	return a.StoreContext
+}
+
+// as finds the first store in the Unwrap chain starting at store that
+// implements T. Wrappers such as Adapt, Passthrough and bucket handles
+// implement Unwrap() StoreContext, so optional interfaces such as Presigner
+// are still found on the store they wrap.
+func as[T any](store any) (T, bool) {
+	for store != nil {
+		if t, ok := store.(T); ok {
+			return t, true
+		}
+
+		wrapper, ok := store.(interface{ Unwrap() StoreContext })
+		if !ok {
+			break
+		}
+		store = wrapper.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (a adapter) Setup() error {
+	return a.SetupContext(context.Background())
+}
+
+func (a adapter) SetBucket(bucketName string) (bool, error) {
+	return a.SetBucketContext(context.Background(), bucketName)
+}
+
+func (a adapter) SetRegion(region string) error {
+	return a.SetRegionContext(context.Background(), region)
+}
+
+func (a adapter) CreateBucket(bucketName string) error {
+	return a.CreateBucketContext(context.Background(), bucketName)
+}
+
+func (a adapter) DeleteBucket(bucketName string) error {
+	return a.DeleteBucketContext(context.Background(), bucketName)
+}
+
+func (a adapter) ListBuckets() ([]Bucket, error) {
+	return a.ListBucketsContext(context.Background())
+}
+
+func (a adapter) CreateObject(objectName string, data io.Reader, contentType string) error {
+	return a.CreateObjectContext(context.Background(), objectName, data, contentType)
+}
+
+func (a adapter) ReadObject(fileName string) ([]byte, error) {
+	return a.ReadObjectContext(context.Background(), fileName)
+}
+
+func (a adapter) UpdateObject(fileName string, data io.Reader) error {
+	return a.UpdateObjectContext(context.Background(), fileName, data)
+}
+
+func (a adapter) DeleteObject(fileName string) error {
+	return a.DeleteObjectContext(context.Background(), fileName)
+}
+
+func (a adapter) ListObjects(bucketName string) ([]*ObjectMetaData, error) {
+	return a.ListObjectsContext(context.Background(), bucketName)
+}
+
+func (a adapter) Exists(fileName string) (bool, *ObjectMetaData, error) {
+	return a.ExistsContext(context.Background(), fileName)
+}
+
+func (a adapter) Metadata(fileName string) (*ObjectMetaData, error) {
+	return a.MetadataContext(context.Background(), fileName)
+}
+
+func (a adapter) CopyObject(fileSource, fileDestination string) error {
+	return a.CopyObjectContext(context.Background(), fileSource, fileDestination)
+}
+
+func (a adapter) MoveObject(fileSource, fileDestination string) error {
+	return a.MoveObjectContext(context.Background(), fileSource, fileDestination)
+}
+
+func (a adapter) CleanUp() error {
+	return a.CleanUpContext(context.Background())
+}
+
+func (a adapter) HealthCheck() error {
+	return a.HealthCheckContext(context.Background())
+}
+
+// ContextReader wraps r so that reads fail with ctx.Err() once ctx is done.
+// Drivers use it to make long io.Copy calls cancellable.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -12,7 +12,22 @@ type NamedConfig interface {
 	DriverName() string
 }
 
-func New(config NamedConfig) (Store, error) {
+// Option configures the Store returned by New.
+type Option func(*options)
+
+type options struct {
+	middleware []Middleware
+}
+
+// WithMiddleware wraps the driver's Store in middleware, the first one
+// outermost. See Chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+func New(config NamedConfig, opts ...Option) (Store, error) {
 	driverName := config.DriverName()
 
 	driver, ok := drivers[driverName]
@@ -20,5 +35,22 @@ func New(config NamedConfig) (Store, error) {
 		return nil, ErrUnknownDriver
 	}
 
-	return driver(config)
+	if v, ok := config.(Validator); ok {
+		err := v.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	store, err := driver(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return Chain(store, o.middleware...), nil
 }
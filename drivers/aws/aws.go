@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"io"
-	"log"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -27,29 +32,48 @@ func init() {
 
 		return NewStore(typed)
 	})
+	objex.RegisterURL("s3", parseURL)
+	objex.RegisterConfig[Config]()
 }
 
 type Config struct {
-	Region       string
-	Bucket       string
-	Endpoint     string
-	AccessKey    string
-	SecretKey    string
-	Token        string
-	UseSSL       bool
-	UsePathStyle bool
+	Region       string `json:"region"`
+	Bucket       string `json:"bucket"`
+	Endpoint     string `json:"endpoint"`
+	AccessKey    string `json:"access_key"`
+	SecretKey    string `json:"secret_key"`
+	Token        string `json:"token"`
+	UseSSL       bool   `json:"use_ssl"`
+	UsePathStyle bool   `json:"use_path_style"`
+	// Logger receives the driver's diagnostics. Nil discards them.
+	Logger *slog.Logger `json:"-"`
 }
 
 func (c Config) DriverName() string {
 	return driverName
 }
 
+// Validate reports a missing AccessKey or SecretKey. Endpoint may be left
+// empty to use AWS itself.
+func (c Config) Validate() error {
+	if c.AccessKey == "" {
+		return objex.ErrInvalidAccessKey
+	}
+
+	if c.SecretKey == "" {
+		return objex.ErrInvalidSecretKey
+	}
+
+	return nil
+}
+
 type Store struct {
-	client     *s3.Client
-	uploader   *manager.Uploader
-	downloader *manager.Downloader
-	bucket     string
-	region     string
+	client    *s3.Client
+	uploader  *manager.Uploader
+	presigner *s3.PresignClient
+	bucket    string
+	region    string
+	logger    *slog.Logger
 }
 
 func NewStore(cfg Config) (*Store, error) {
@@ -89,16 +113,22 @@ func NewStore(cfg Config) (*Store, error) {
 		return nil, objex.ErrClientInit
 	}
 
+	logger := cfg.Logger
+	if logger == nil {
+		logger = objex.DiscardLogger()
+	}
+
 	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.UsePathStyle = cfg.UsePathStyle
 	})
 
 	return &Store{
-		client:     client,
-		uploader:   manager.NewUploader(client),
-		downloader: manager.NewDownloader(client),
-		bucket:     cfg.Bucket,
-		region:     cfg.Region,
+		client:    client,
+		uploader:  manager.NewUploader(client),
+		presigner: s3.NewPresignClient(client),
+		bucket:    cfg.Bucket,
+		region:    cfg.Region,
+		logger:    logger.With("driver", driverName),
 	}, nil
 }
 
@@ -106,59 +136,114 @@ func (s *Store) DriverName() string {
 	return driverName
 }
 
-func (s *Store) Setup() error { return nil }
+func (s *Store) Setup() error {
+	return s.SetupContext(context.Background())
+}
 
-func (s *Store) HealthCheck() error {
-	_, err := s.client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
-	if err != nil {
-		return objex.ErrClientInit
+func (s *Store) SetupContext(ctx context.Context) error { return nil }
+
+// Capabilities reports presigning, multipart uploads, server-side copies
+// and versioning, all of which S3 provides. S3 object lock is not
+// reported, since objex has no API to use it.
+func (s *Store) Capabilities() objex.Capabilities {
+	return objex.Capabilities{
+		Presign:        true,
+		Multipart:      true,
+		ServerSideCopy: true,
+		Versioning:     true,
 	}
+}
 
-	return nil
+func (s *Store) HealthCheck() error {
+	return s.HealthCheckContext(context.Background())
+}
+
+func (s *Store) HealthCheckContext(ctx context.Context) error {
+	_, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	return toError("HealthCheck", "", "", err)
 }
 
 func (s *Store) SetBucket(bucketName string) (bool, error) {
-	s.bucket = bucketName
-	_, err := s.client.HeadBucket(context.TODO(), &s3.HeadBucketInput{
+	return s.SetBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) SetBucketContext(ctx context.Context, bucketName string) (bool, error) {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(bucketName),
 	})
 	if err != nil {
-		return false, objex.ErrBucketNotFound
+		e := toError("SetBucket", bucketName, "", err).(*objex.Error)
+		// HEAD answers a missing bucket with a bare 404, read as a
+		// missing object.
+		if e.Kind == objex.ErrObjectNotFound {
+			e.Kind = objex.ErrBucketNotFound
+		}
+		return false, e
 	}
+	s.bucket = bucketName
 	return true, nil
 }
 
+// CurrentBucket returns the bucket set by Config.Bucket or SetBucket.
+func (s *Store) CurrentBucket() string {
+	return s.bucket
+}
+
+// Bucket returns a handle bound to bucketName that shares the store's client.
+func (s *Store) Bucket(bucketName string) objex.BucketHandle {
+	return objex.NewBucketHandle(bucketName, &Store{
+		client:    s.client,
+		uploader:  s.uploader,
+		presigner: s.presigner,
+		bucket:    bucketName,
+		region:    s.region,
+		logger:    s.logger,
+	})
+}
+
 func (s *Store) SetRegion(region string) error {
+	return s.SetRegionContext(context.Background(), region)
+}
+
+func (s *Store) SetRegionContext(ctx context.Context, region string) error {
 	s.region = region
 	return nil
 }
 
 func (s *Store) CreateBucket(name string) error {
+	return s.CreateBucketContext(context.Background(), name)
+}
+
+func (s *Store) CreateBucketContext(ctx context.Context, name string) error {
 	if name == "" {
 		return objex.ErrInvalidBucketName
 	}
 
-	_, err := s.client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
+	_, err := s.client.CreateBucket(ctx, &s3.CreateBucketInput{
 		Bucket: aws.String(name),
 	})
-	if err != nil {
-		return objex.ErrBucketAlreadyExists
-	}
-
-	return nil
+	return toError("CreateBucket", name, "", err)
 }
 
 func (s *Store) DeleteBucket(name string) error {
-	_, err := s.client.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{
+	return s.DeleteBucketContext(context.Background(), name)
+}
+
+func (s *Store) DeleteBucketContext(ctx context.Context, name string) error {
+	_, err := s.client.DeleteBucket(ctx, &s3.DeleteBucketInput{
 		Bucket: aws.String(name),
 	})
-	return err
+	return toError("DeleteBucket", name, "", err)
 }
 
 func (s *Store) ListBuckets() ([]objex.Bucket, error) {
-	out, err := s.client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+	return s.ListBucketsContext(context.Background())
+}
+
+func (s *Store) ListBucketsContext(ctx context.Context) ([]objex.Bucket, error) {
+	out, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
-		return nil, err
+		return nil, toError("ListBuckets", "", "", err)
 	}
 
 	var buckets []objex.Bucket
@@ -173,126 +258,334 @@ func (s *Store) ListBuckets() ([]objex.Bucket, error) {
 }
 
 func (s *Store) CreateObject(name string, data io.Reader, contentType string) error {
+	return s.CreateObjectContext(context.Background(), name, data, contentType)
+}
+
+func (s *Store) CreateObjectContext(ctx context.Context, name string, data io.Reader, contentType string) error {
+	return s.PutObject(ctx, name, data, objex.PutOptions{ContentType: contentType})
+}
+
+func (s *Store) PutObject(ctx context.Context, name string, data io.Reader, opts objex.PutOptions) error {
 	bucket, key, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
 		return err
 	}
 
-	rd, _, err := objex.GetStreamSize(data)
-	if err != nil {
-		return objex.ErrPreconditionFailed
+	// S3 only supports ETag conditions on writes.
+	if opts.Conditions.HasDates() {
+		return objex.ErrNotSupported
 	}
 
-	_, err = s.uploader.Upload(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        rd,
-		ContentType: aws.String(contentType),
+	// The upload manager sends data that fits in one part as a single PUT
+	// and anything larger, or of unknown size, in parts it reads one at a
+	// time, so a non-seekable body is never held in memory whole.
+	_, err = s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		Body:               data,
+		ContentType:        optionalString(opts.ContentType),
+		ContentDisposition: optionalString(opts.ContentDisposition),
+		ContentEncoding:    optionalString(opts.ContentEncoding),
+		CacheControl:       optionalString(opts.CacheControl),
+		ContentLanguage:    optionalString(opts.ContentLanguage),
+		Metadata:           objex.NormalizeUserMetadata(opts.UserMetadata),
+		IfMatch:            optionalString(opts.Conditions.IfMatch),
+		IfNoneMatch:        optionalString(opts.Conditions.IfNoneMatch),
 	})
-	return err
+	return toError("PutObject", bucket, key, err)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
 }
 
 func (s *Store) ReadObject(name string) ([]byte, error) {
+	return s.ReadObjectContext(context.Background(), name)
+}
+
+func (s *Store) ReadObjectContext(ctx context.Context, name string) ([]byte, error) {
+	return objex.ReadObject(ctx, s, name)
+}
+
+func (s *Store) OpenObject(ctx context.Context, name string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
 	bucket, key, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	buf := manager.NewWriteAtBuffer([]byte{})
-	_, err = s.downloader.Download(context.TODO(), buf, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
+	body, meta, err := s.openObject(ctx, bucket, key, "", opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, toError("OpenObject", bucket, key, err)
+	}
+	return body, meta, nil
+}
+
+// openObject opens versionID of the object, or its latest version when
+// versionID is empty.
+func (s *Store) openObject(ctx context.Context, bucket, key, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	input := &s3.GetObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		VersionId:         optionalString(versionID),
+		IfMatch:           optionalString(opts.Conditions.IfMatch),
+		IfNoneMatch:       optionalString(opts.Conditions.IfNoneMatch),
+		IfModifiedSince:   optionalTime(opts.Conditions.IfModifiedSince),
+		IfUnmodifiedSince: optionalTime(opts.Conditions.IfUnmodifiedSince),
+	}
+	// "bytes=0-" cannot be satisfied by an empty object, so the zero Range
+	// sends no header at all.
+	if opts.Range != nil && *opts.Range != (objex.Range{}) {
+		if err := opts.Range.Validate(); err != nil {
+			return nil, nil, err
+		}
+		input.Range = aws.String(opts.Range.Header())
 	}
 
-	return buf.Bytes(), nil
+	out, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &objex.ObjectMetaData{
+		Key:                key,
+		Size:               aws.ToInt64(out.ContentLength),
+		ContentType:        aws.ToString(out.ContentType),
+		LastModified:       aws.ToTime(out.LastModified).Format(time.RFC3339),
+		ETag:               etag(out.ETag),
+		ContentDisposition: aws.ToString(out.ContentDisposition),
+		ContentEncoding:    aws.ToString(out.ContentEncoding),
+		CacheControl:       aws.ToString(out.CacheControl),
+		ContentLanguage:    aws.ToString(out.ContentLanguage),
+		UserMetadata:       objex.NormalizeUserMetadata(out.Metadata),
+		VersionID:          aws.ToString(out.VersionId),
+	}
+	return out.Body, meta, nil
 }
 
 func (s *Store) UpdateObject(name string, data io.Reader) error {
-	exists, meta, err := s.Exists(name)
-	if err != nil || !exists {
+	return s.UpdateObjectContext(context.Background(), name, data)
+}
+
+func (s *Store) UpdateObjectContext(ctx context.Context, name string, data io.Reader) error {
+	exists, meta, err := s.ExistsContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
 		return objex.ErrObjectNotFound
 	}
-	return s.CreateObject(name, data, meta.ContentType)
+	return s.PutObject(ctx, name, data, meta.PutOptions())
 }
 
 func (s *Store) DeleteObject(name string) error {
+	return s.DeleteObjectContext(context.Background(), name)
+}
+
+func (s *Store) DeleteObjectContext(ctx context.Context, name string) error {
+	return s.DeleteObjectIf(ctx, name, objex.Conditions{})
+}
+
+func (s *Store) DeleteObjectIf(ctx context.Context, name string, cond objex.Conditions) error {
 	bucket, key, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+	// S3 only supports If-Match on deletes.
+	if cond.IfNoneMatch != "" || cond.HasDates() {
+		return objex.ErrNotSupported
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		IfMatch: optionalString(cond.IfMatch),
 	})
-	return err
+	return toError("DeleteObject", bucket, key, err)
 }
 
 func (s *Store) ListObjects(bucketName string) ([]*objex.ObjectMetaData, error) {
-	if s.bucket == "" {
-		s.bucket = bucketName
+	return s.ListObjectsContext(context.Background(), bucketName)
+}
+
+func (s *Store) ListObjectsContext(ctx context.Context, bucketName string) ([]*objex.ObjectMetaData, error) {
+	if bucketName == "" {
+		bucketName = s.bucket
 	}
 
-	out, err := s.client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
-		Bucket: aws.String(s.bucket),
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
 	})
+
+	var items []*objex.ObjectMetaData
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, toError("ListObjects", bucketName, "", err)
+		}
+
+		for _, obj := range out.Contents {
+			items = append(items, objectMetaData(obj))
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) ListObjectsPage(ctx context.Context, bucketName string, opts objex.ListOptions) (*objex.ListResult, error) {
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+	if opts.Prefix != "" {
+		input.Prefix = aws.String(opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		input.Delimiter = aws.String(opts.Delimiter)
+	}
+	if opts.StartAfter != "" {
+		input.StartAfter = aws.String(opts.StartAfter)
+	}
+	if opts.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(opts.ContinuationToken)
+	}
+	if opts.MaxKeys > 0 {
+		input.MaxKeys = aws.Int32(int32(opts.MaxKeys))
+	}
+
+	out, err := s.client.ListObjectsV2(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, toError("ListObjectsPage", bucketName, "", err)
 	}
 
-	var items []*objex.ObjectMetaData
+	result := &objex.ListResult{
+		IsTruncated:           aws.ToBool(out.IsTruncated),
+		NextContinuationToken: aws.ToString(out.NextContinuationToken),
+	}
 	for _, obj := range out.Contents {
-		items = append(items, &objex.ObjectMetaData{
-			Key:          *obj.Key,
-			Size:         *obj.Size,
-			LastModified: obj.LastModified.Format(time.RFC3339),
-			ETag:         *obj.ETag,
-			ContentType:  "application/octet-stream", // AWS S3 doesn't return this in List
-		})
+		result.Objects = append(result.Objects, objectMetaData(obj))
+	}
+	for _, prefix := range out.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, aws.ToString(prefix.Prefix))
+	}
+	return result, nil
+}
+
+func (s *Store) Objects(ctx context.Context, bucketName string, opts objex.ListOptions) iter.Seq2[*objex.ObjectMetaData, error] {
+	return func(yield func(*objex.ObjectMetaData, error) bool) {
+		if bucketName == "" {
+			bucketName = s.bucket
+		}
+
+		input := &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucketName),
+		}
+		if opts.Prefix != "" {
+			input.Prefix = aws.String(opts.Prefix)
+		}
+		if opts.StartAfter != "" {
+			input.StartAfter = aws.String(opts.StartAfter)
+		}
+		if opts.ContinuationToken != "" {
+			input.ContinuationToken = aws.String(opts.ContinuationToken)
+		}
+		if opts.MaxKeys > 0 {
+			input.MaxKeys = aws.Int32(int32(opts.MaxKeys))
+		}
+
+		paginator := s3.NewListObjectsV2Paginator(s.client, input)
+		for paginator.HasMorePages() {
+			out, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(nil, toError("Objects", bucketName, "", err))
+				return
+			}
+
+			for _, obj := range out.Contents {
+				if !yield(objectMetaData(obj), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func objectMetaData(obj types.Object) *objex.ObjectMetaData {
+	return &objex.ObjectMetaData{
+		Key:          aws.ToString(obj.Key),
+		Size:         aws.ToInt64(obj.Size),
+		LastModified: aws.ToTime(obj.LastModified).Format(time.RFC3339),
+		ETag:         etag(obj.ETag),
+		ContentType:  "application/octet-stream", // AWS S3 doesn't return this in List
 	}
-	return items, nil
 }
 
 func (s *Store) Exists(name string) (bool, *objex.ObjectMetaData, error) {
+	return s.ExistsContext(context.Background(), name)
+}
+
+func (s *Store) ExistsContext(ctx context.Context, name string) (bool, *objex.ObjectMetaData, error) {
 	bucket, key, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
 		return false, nil, err
 	}
 
-	head, err := s.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		var nf *types.NotFound
-		if errors.As(err, &nf) {
+		err = toError("Exists", bucket, key, err)
+		if errors.Is(err, objex.ErrObjectNotFound) {
 			return false, nil, nil
 		}
 		return false, nil, err
 	}
 
 	meta := &objex.ObjectMetaData{
-		Key:          key,
-		Size:         *head.ContentLength,
-		ContentType:  aws.ToString(head.ContentType),
-		LastModified: head.LastModified.Format(time.RFC3339),
-		ETag:         aws.ToString(head.ETag),
+		Key:                key,
+		Size:               *head.ContentLength,
+		ContentType:        aws.ToString(head.ContentType),
+		LastModified:       head.LastModified.Format(time.RFC3339),
+		ETag:               etag(head.ETag),
+		ContentDisposition: aws.ToString(head.ContentDisposition),
+		ContentEncoding:    aws.ToString(head.ContentEncoding),
+		CacheControl:       aws.ToString(head.CacheControl),
+		ContentLanguage:    aws.ToString(head.ContentLanguage),
+		UserMetadata:       objex.NormalizeUserMetadata(head.Metadata),
 	}
 	return true, meta, nil
 }
 
 func (s *Store) Metadata(name string) (*objex.ObjectMetaData, error) {
-	ok, meta, err := s.Exists(name)
-	if err != nil || !ok {
+	return s.MetadataContext(context.Background(), name)
+}
+
+func (s *Store) MetadataContext(ctx context.Context, name string) (*objex.ObjectMetaData, error) {
+	ok, meta, err := s.ExistsContext(ctx, name)
+	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, objex.ErrObjectNotFound
+	}
 	return meta, nil
 }
 
 func (s *Store) CopyObject(src, dest string) error {
+	return s.CopyObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) CopyObjectContext(ctx context.Context, src, dest string) error {
+	return s.CopyObjectIf(ctx, src, dest, objex.CopyConditions{})
+}
+
+func (s *Store) CopyObjectIf(ctx context.Context, src, dest string, cond objex.CopyConditions) error {
 	srcBucket, srcKey, err := objex.SplitPath(s.bucket, src)
 	if err != nil {
 		return err
@@ -302,24 +595,91 @@ func (s *Store) CopyObject(src, dest string) error {
 		return err
 	}
 
-	source := srcBucket + "/" + srcKey
-	_, err = s.client.CopyObject(context.TODO(), &s3.CopyObjectInput{
-		Bucket:     aws.String(destBucket),
-		Key:        aws.String(destKey),
-		CopySource: aws.String(source),
+	// CopyObject has no conditions on the destination.
+	if !cond.Destination.IsZero() {
+		return objex.ErrNotSupported
+	}
+
+	// CopySource must be URL-encoded.
+	source := url.PathEscape(srcBucket) + "/" + escapeKey(srcKey)
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:                      aws.String(destBucket),
+		Key:                         aws.String(destKey),
+		CopySource:                  aws.String(source),
+		CopySourceIfMatch:           optionalString(cond.Source.IfMatch),
+		CopySourceIfNoneMatch:       optionalString(cond.Source.IfNoneMatch),
+		CopySourceIfModifiedSince:   optionalTime(cond.Source.IfModifiedSince),
+		CopySourceIfUnmodifiedSince: optionalTime(cond.Source.IfUnmodifiedSince),
 	})
-	return err
+	return toError("CopyObject", srcBucket, srcKey, err)
+}
+
+// escapeKey URL-encodes each segment of key, keeping the slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
 }
 
 func (s *Store) MoveObject(src, dest string) error {
-	err := s.CopyObject(src, dest)
+	return s.MoveObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) MoveObjectContext(ctx context.Context, src, dest string) error {
+	err := s.CopyObjectContext(ctx, src, dest)
 	if err != nil {
 		return err
 	}
-	return s.DeleteObject(src)
+	return s.DeleteObjectContext(ctx, src)
+}
+
+func (s *Store) PresignObject(ctx context.Context, method, name string, expires time.Duration) (string, error) {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return "", err
+	}
+
+	withExpires := s3.WithPresignExpires(expires)
+
+	var req *v4.PresignedHTTPRequest
+	switch method {
+	case http.MethodGet:
+		req, err = s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, withExpires)
+	case http.MethodPut:
+		req, err = s.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, withExpires)
+	case http.MethodHead:
+		req, err = s.presigner.PresignHeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, withExpires)
+	case http.MethodDelete:
+		req, err = s.presigner.PresignDeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, withExpires)
+	default:
+		return "", objex.ErrNotSupported
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return req.URL, nil
 }
 
 func (s *Store) CleanUp() error {
-	log.Println("[Objex AWS] CleanUp called — no action needed")
+	return s.CleanUpContext(context.Background())
+}
+
+func (s *Store) CleanUpContext(ctx context.Context) error {
+	s.logger.DebugContext(ctx, "nothing to clean up")
 	return nil
 }
@@ -0,0 +1,69 @@
+package aws_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/aws"
+	"github.com/brian-nunez/objex/objextest"
+)
+
+// TestConformance runs against the S3 service named by OBJEX_TEST_S3_URL,
+// e.g. the MinIO server in test-object-store:
+//
+//	OBJEX_TEST_S3_URL='s3://admin:adminpassword@localhost:9000?ssl=false&path_style=true&region=us-east-1'
+func TestConformance(t *testing.T) {
+	rawURL := os.Getenv("OBJEX_TEST_S3_URL")
+	if rawURL == "" {
+		t.Skip("OBJEX_TEST_S3_URL is not set")
+	}
+
+	objextest.Run(t, func(t *testing.T) objex.Store {
+		store, err := objex.Open(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    aws.Config
+		wantErr error
+	}{
+		{
+			url: "s3://key:secret@localhost:9000/photos?region=us-east-1&token=session&path_style=true&ssl=false",
+			want: aws.Config{
+				Endpoint:     "localhost:9000",
+				AccessKey:    "key",
+				SecretKey:    "secret",
+				Token:        "session",
+				Region:       "us-east-1",
+				Bucket:       "photos",
+				UsePathStyle: true,
+			},
+		},
+		{url: "s3://key:secret@/photos", want: aws.Config{AccessKey: "key", SecretKey: "secret", Bucket: "photos", UseSSL: true}},
+		{url: "s3://localhost:9000", want: aws.Config{Endpoint: "localhost:9000", UseSSL: true}},
+		{url: "s3://localhost:9000/photos/thumbnails", wantErr: objex.ErrInvalidEndpoint},
+		{url: "s3://localhost:9000?ssl=maybe", wantErr: objex.ErrInvalidEndpoint},
+		{url: "s3://localhost:9000?acl=private", wantErr: objex.ErrInvalidEndpoint},
+	}
+
+	for _, test := range tests {
+		config, err := objex.ParseURL(test.url)
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("ParseURL(%s): got error %v, want %v", test.url, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil || config != test.want {
+			t.Errorf("ParseURL(%s): got %+v, %v, want %+v", test.url, config, err, test.want)
+		}
+	}
+}
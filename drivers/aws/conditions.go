@@ -0,0 +1,21 @@
+package aws
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
+
+// etag returns an S3 ETag without the quotes S3 puts around it, as the
+// other drivers report it.
+func etag(value *string) string {
+	return strings.Trim(aws.ToString(value), `"`)
+}
@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+	"github.com/brian-nunez/objex"
+)
+
+// toError describes a failed S3 call as an *objex.Error, with the kind
+// taken from the S3 error code. Errors that are not S3 responses, such as
+// network failures or a canceled context, are kept as the cause without a
+// kind.
+func toError(op, bucket, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &objex.Error{Op: op, Bucket: bucket, Key: key, Err: err}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		e.Code = apiErr.ErrorCode()
+	}
+	var response interface{ HTTPStatusCode() int }
+	if errors.As(err, &response) {
+		e.StatusCode = response.HTTPStatusCode()
+	}
+	if e.Code != "" || e.StatusCode != 0 {
+		e.Kind = objex.S3ErrorKind(e.Code, e.StatusCode)
+	}
+
+	return e
+}
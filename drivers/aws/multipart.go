@@ -0,0 +1,179 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/brian-nunez/objex"
+)
+
+func (s *Store) CreateMultipartUpload(ctx context.Context, name string, opts objex.PutOptions) (string, error) {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		ContentType:        optionalString(opts.ContentType),
+		ContentDisposition: optionalString(opts.ContentDisposition),
+		ContentEncoding:    optionalString(opts.ContentEncoding),
+		CacheControl:       optionalString(opts.CacheControl),
+		ContentLanguage:    optionalString(opts.ContentLanguage),
+		Metadata:           objex.NormalizeUserMetadata(opts.UserMetadata),
+	})
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, key, err)
+	}
+
+	return aws.ToString(out.UploadId), nil
+}
+
+func (s *Store) UploadPart(ctx context.Context, name, uploadID string, partNumber int, data io.Reader) (objex.Part, error) {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return objex.Part{}, err
+	}
+
+	rd, size, err := objex.GetStreamSize(data)
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, key, err)
+	}
+
+	out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(partNumber)),
+		Body:          rd,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, key, err)
+	}
+
+	return objex.Part{
+		PartNumber:   partNumber,
+		ETag:         etag(out.ETag),
+		Size:         size,
+		LastModified: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func (s *Store) ListParts(ctx context.Context, name, uploadID string) ([]objex.Part, error) {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return nil, err
+	}
+
+	paginator := s3.NewListPartsPaginator(s.client, &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+
+	var parts []objex.Part
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, toError("ListParts", bucket, key, err)
+		}
+
+		for _, part := range out.Parts {
+			parts = append(parts, objex.Part{
+				PartNumber:   int(aws.ToInt32(part.PartNumber)),
+				ETag:         etag(part.ETag),
+				Size:         aws.ToInt64(part.Size),
+				LastModified: aws.ToTime(part.LastModified).Format(time.RFC3339),
+			})
+		}
+	}
+	return parts, nil
+}
+
+func (s *Store) CompleteMultipartUpload(ctx context.Context, name, uploadID string, parts []objex.Part) error {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+
+	// Like S3's InvalidPartOrder, parts must be listed once each, in
+	// ascending order. They are checked here rather than sorted, so every
+	// driver rejects the same lists.
+	if len(parts) == 0 {
+		return toError("CompleteMultipartUpload", bucket, key, objex.ErrInvalidPart)
+	}
+
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return toError("CompleteMultipartUpload", bucket, key, objex.ErrInvalidPart)
+		}
+
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(int32(part.PartNumber)),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completed,
+		},
+	})
+	return toError("CompleteMultipartUpload", bucket, key, err)
+}
+
+func (s *Store) AbortMultipartUpload(ctx context.Context, name, uploadID string) error {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	return toError("AbortMultipartUpload", bucket, key, err)
+}
+
+func (s *Store) ListMultipartUploads(ctx context.Context, bucketName, prefix string) ([]objex.MultipartUpload, error) {
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	paginator := s3.NewListMultipartUploadsPaginator(s.client, input)
+
+	var uploads []objex.MultipartUpload
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, toError("ListMultipartUploads", bucketName, "", err)
+		}
+
+		for _, upload := range out.Uploads {
+			uploads = append(uploads, objex.MultipartUpload{
+				Key:       aws.ToString(upload.Key),
+				UploadID:  aws.ToString(upload.UploadId),
+				Initiated: aws.ToTime(upload.Initiated).Format(time.RFC3339),
+			})
+		}
+	}
+	return uploads, nil
+}
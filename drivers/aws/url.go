@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/brian-nunez/objex"
+)
+
+// parseURL reads an s3:// store URL:
+//
+//	s3://key:secret@host:9000/bucket?region=us-east-1&path_style=true
+//
+// The host is the endpoint of an S3 compatible service; leave it out
+// (s3://key:secret@/bucket) for AWS itself. The path names the current
+// bucket, if any. Parameters are region, token, path_style and ssl, which
+// defaults to true.
+func parseURL(u *url.URL) (objex.NamedConfig, error) {
+	config := Config{
+		Endpoint: u.Host,
+		UseSSL:   true,
+	}
+
+	if u.User != nil {
+		config.AccessKey = u.User.Username()
+		config.SecretKey, _ = u.User.Password()
+	}
+
+	config.Bucket = strings.Trim(u.Path, "/")
+	if strings.Contains(config.Bucket, "/") {
+		return nil, objex.InvalidURL("the path must be a single bucket name")
+	}
+
+	var err error
+	for name := range u.Query() {
+		value := u.Query().Get(name)
+		switch name {
+		case "region":
+			config.Region = value
+		case "token":
+			config.Token = value
+		case "path_style":
+			config.UsePathStyle, err = strconv.ParseBool(value)
+		case "ssl":
+			config.UseSSL, err = strconv.ParseBool(value)
+		default:
+			return nil, objex.InvalidURL("unknown parameter " + name)
+		}
+		if err != nil {
+			return nil, objex.InvalidURL("invalid " + name + " " + strconv.Quote(value))
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/brian-nunez/objex"
+)
+
+func (s *Store) SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	status := types.BucketVersioningStatusSuspended
+	if enabled {
+		status = types.BucketVersioningStatusEnabled
+	}
+
+	_, err := s.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  aws.String(bucketName),
+		VersioningConfiguration: &types.VersioningConfiguration{Status: status},
+	})
+	return toError("SetBucketVersioning", bucketName, "", err)
+}
+
+func (s *Store) BucketVersioning(ctx context.Context, bucketName string) (objex.VersioningStatus, error) {
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	out, err := s.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		return objex.VersioningOff, toError("BucketVersioning", bucketName, "", err)
+	}
+	return objex.VersioningStatus(out.Status), nil
+}
+
+func (s *Store) ListObjectVersions(ctx context.Context, bucketName, prefix string) ([]objex.ObjectVersion, error) {
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	paginator := s3.NewListObjectVersionsPaginator(s.client, input)
+
+	// S3 lists versions and delete markers apart, each in key order and
+	// newest first, so they are merged by modification time.
+	type listed struct {
+		version  objex.ObjectVersion
+		modified time.Time
+	}
+	var all []listed
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, toError("ListObjectVersions", bucketName, "", err)
+		}
+
+		for _, v := range out.Versions {
+			all = append(all, listed{
+				version: objex.ObjectVersion{
+					Key:       aws.ToString(v.Key),
+					VersionID: aws.ToString(v.VersionId),
+					IsLatest:  aws.ToBool(v.IsLatest),
+					Size:      aws.ToInt64(v.Size),
+					ETag:      etag(v.ETag),
+				},
+				modified: aws.ToTime(v.LastModified),
+			})
+		}
+		for _, m := range out.DeleteMarkers {
+			all = append(all, listed{
+				version: objex.ObjectVersion{
+					Key:            aws.ToString(m.Key),
+					VersionID:      aws.ToString(m.VersionId),
+					IsLatest:       aws.ToBool(m.IsLatest),
+					IsDeleteMarker: true,
+				},
+				modified: aws.ToTime(m.LastModified),
+			})
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b listed) int {
+		if c := strings.Compare(a.version.Key, b.version.Key); c != 0 {
+			return c
+		}
+		if a.version.IsLatest != b.version.IsLatest {
+			if a.version.IsLatest {
+				return -1
+			}
+			return 1
+		}
+		return b.modified.Compare(a.modified)
+	})
+
+	versions := make([]objex.ObjectVersion, len(all))
+	for i, l := range all {
+		versions[i] = l.version
+		versions[i].LastModified = l.modified.Format(time.RFC3339)
+	}
+	return versions, nil
+}
+
+func (s *Store) OpenObjectVersion(ctx context.Context, name, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if versionID == "" {
+		return nil, nil, objex.ErrVersionNotFound
+	}
+
+	body, meta, err := s.openObject(ctx, bucket, key, versionID, opts)
+	if err != nil {
+		return nil, nil, toError("OpenObjectVersion", bucket, key, err)
+	}
+	return body, meta, nil
+}
+
+func (s *Store) DeleteObjectVersion(ctx context.Context, name, versionID string) error {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+	if versionID == "" {
+		return objex.ErrVersionNotFound
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	return toError("DeleteObjectVersion", bucket, key, err)
+}
+
+func (s *Store) RestoreObjectVersion(ctx context.Context, name, versionID string) error {
+	bucket, key, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+	if versionID == "" {
+		return objex.ErrVersionNotFound
+	}
+
+	source := url.PathEscape(bucket) + "/" + escapeKey(key) + "?versionId=" + url.QueryEscape(versionID)
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(source),
+	})
+	return toError("RestoreObjectVersion", bucket, key, err)
+}
@@ -0,0 +1,116 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile is written in the bucket's hidden directory and renamed into
+// place by commit, so readers see either the previous file or the whole new
+// one, as with an S3 PUT, and a failed write leaves nothing behind.
+type atomicFile struct {
+	*os.File
+	path      string
+	synced    bool
+	committed bool
+}
+
+func (s *Store) tempDir(bucket string) string {
+	return filepath.Join(s.basePath, bucket, hiddenDir, "tmp")
+}
+
+// createAtomic starts writing the file at path inside bucket, which must
+// exist. The temporary file is in the same bucket directory, so the rename
+// never crosses file systems.
+func (s *Store) createAtomic(bucket, path string) (*atomicFile, error) {
+	err := s.requireBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := s.tempDir(bucket)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(dir, "write-")
+	if err != nil {
+		return nil, err
+	}
+
+	// CreateTemp makes the file private.
+	err = file.Chmod(0644)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return &atomicFile{File: file, path: path}, nil
+}
+
+// sync flushes the file to disk and closes it, so that it can be described
+// before it is renamed into place.
+func (f *atomicFile) sync() error {
+	err := f.Sync()
+	closeErr := f.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	f.synced = true
+	return nil
+}
+
+// commit flushes the file to disk and renames it over path.
+func (f *atomicFile) commit() error {
+	if !f.synced {
+		err := f.sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := renameInto(f.Name(), f.path)
+	if err != nil {
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+// rename is os.Rename. Tests replace it to make renames fail.
+var rename = os.Rename
+
+// renameInto renames oldPath to newPath, creating the parent directories of
+// newPath and trying again if a concurrent delete pruned them in between.
+func renameInto(oldPath, newPath string) error {
+	for attempt := 0; ; attempt++ {
+		err := os.MkdirAll(filepath.Dir(newPath), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = rename(oldPath, newPath)
+		if errors.Is(err, os.ErrNotExist) && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+// abort removes the temporary file unless it was committed. It is meant to
+// be deferred right after createAtomic.
+func (f *atomicFile) abort() {
+	if f.committed {
+		return
+	}
+	if !f.synced {
+		f.Close()
+	}
+	os.Remove(f.Name())
+}
@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+)
+
+// failRenames makes renames onto paths for which fail returns true fail
+// until the test ends.
+func failRenames(t *testing.T, fail func(newPath string) bool) {
+	t.Cleanup(func() { rename = os.Rename })
+	rename = func(oldPath, newPath string) error {
+		if fail(newPath) {
+			return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EIO}
+		}
+		return os.Rename(oldPath, newPath)
+	}
+}
+
+func TestFailedCommitKeepsObject(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(s *Store) error
+	}{
+		{"PutObject", func(s *Store) error {
+			return s.PutObject(context.Background(), "doc.txt", strings.NewReader("new"), objex.PutOptions{ContentType: "text/html"})
+		}},
+		{"CopyObject", func(s *Store) error {
+			return s.CopyObject("other.txt", "doc.txt")
+		}},
+		{"MoveObject", func(s *Store) error {
+			return s.MoveObject("other.txt", "doc.txt")
+		}},
+	}
+
+	for _, test := range tests {
+		for _, failing := range []string{"sidecar", "data"} {
+			t.Run(test.name+"/"+failing, func(t *testing.T) {
+				s, err := NewStore(Config{BasePath: t.TempDir()})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ctx := context.Background()
+				err = s.CreateBucket("docs")
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = s.SetBucket("docs")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for key, body := range map[string]string{"doc.txt": "old", "other.txt": "other"} {
+					err = s.PutObject(ctx, key, strings.NewReader(body), objex.PutOptions{ContentType: "text/plain"})
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				before, err := s.Metadata("doc.txt")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				target := s.sidecarPath("docs", "doc.txt")
+				if failing == "data" {
+					target = filepath.Join(s.basePath, "docs", "doc.txt")
+				}
+				failRenames(t, func(newPath string) bool { return newPath == target })
+
+				err = test.write(s)
+				if err == nil {
+					t.Fatalf("%s succeeded with the %s rename failing", test.name, failing)
+				}
+
+				data, err := s.ReadObject("doc.txt")
+				if err != nil || string(data) != "old" {
+					t.Errorf("ReadObject: got %q, %v, want %q", data, err, "old")
+				}
+				after, err := s.Metadata("doc.txt")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if after.ContentType != before.ContentType || after.ETag != before.ETag || after.LastModified != before.LastModified {
+					t.Errorf("Metadata: got %+v, want %+v", after, before)
+				}
+
+				tmp, err := os.ReadDir(s.tempDir("docs"))
+				if err != nil || len(tmp) != 0 {
+					t.Errorf("temporary files left behind: %v, %v", tmp, err)
+				}
+			})
+		}
+	}
+}
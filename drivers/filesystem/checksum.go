@@ -0,0 +1,57 @@
+package filesystem
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// checksums hashes object data as it is written. The MD5 is always computed
+// because it is the ETag; SHA-256 and CRC32C only when the Store asks for
+// them.
+type checksums struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+	crc32c hash.Hash
+}
+
+func (s *Store) newChecksums() *checksums {
+	c := &checksums{md5: md5.New()}
+	if s.checksumSHA256 {
+		c.sha256 = sha256.New()
+	}
+	if s.checksumCRC32C {
+		c.crc32c = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	}
+	return c
+}
+
+// writer returns a writer that feeds every hash in use.
+func (c *checksums) writer() io.Writer {
+	writers := []io.Writer{c.md5}
+	if c.sha256 != nil {
+		writers = append(writers, c.sha256)
+	}
+	if c.crc32c != nil {
+		writers = append(writers, c.crc32c)
+	}
+	return io.MultiWriter(writers...)
+}
+
+// store records the checksums in sc in the formats S3 returns them: the
+// ETag as hex and the others base64 encoded.
+func (c *checksums) store(sc *sidecar) {
+	sc.ETag = hex.EncodeToString(c.md5.Sum(nil))
+	sc.ChecksumSHA256 = ""
+	sc.ChecksumCRC32C = ""
+	if c.sha256 != nil {
+		sc.ChecksumSHA256 = base64.StdEncoding.EncodeToString(c.sha256.Sum(nil))
+	}
+	if c.crc32c != nil {
+		sc.ChecksumCRC32C = base64.StdEncoding.EncodeToString(c.crc32c.Sum(nil))
+	}
+}
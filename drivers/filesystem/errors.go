@@ -0,0 +1,56 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+
+	"github.com/brian-nunez/objex"
+)
+
+// toError describes a failed filesystem call as an *objex.Error. Errors that
+// already are Err* sentinels keep their kind; os errors are mapped to the
+// closest sentinel.
+func toError(op, bucket, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *objex.Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	// Objects without a bucket live in the root bucket.
+	if bucket == rootBucket {
+		bucket = ""
+	}
+
+	return &objex.Error{
+		Kind:   errorKind(err),
+		Op:     op,
+		Bucket: bucket,
+		Key:    key,
+		Err:    err,
+	}
+}
+
+func errorKind(err error) error {
+	if kind := objex.KindOf(err); kind != nil {
+		return kind
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return objex.ErrObjectNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return objex.ErrAccessDenied
+	case errors.Is(err, syscall.ENOTEMPTY):
+		return objex.ErrBucketNotEmpty
+	case errors.Is(err, syscall.ENAMETOOLONG), errors.Is(err, syscall.ENOTDIR), errors.Is(err, syscall.EISDIR):
+		// A key that is too long, or that runs through or onto another
+		// object's path, such as "a/b" next to "a".
+		return objex.ErrInvalidObjectName
+	}
+	return nil
+}
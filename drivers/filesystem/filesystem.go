@@ -1,12 +1,19 @@
 package filesystem
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
-	"log"
+	"iter"
+	"log/slog"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,66 +30,237 @@ func init() {
 		}
 		return NewStore(conf)
 	})
+	objex.RegisterURL("file", parseURL)
+	objex.RegisterConfig[Config]()
 }
 
 type Config struct {
-	BasePath string
+	BasePath string `json:"base_path"`
+	// Bucket, when set, is used as the current bucket.
+	Bucket string `json:"bucket"`
+	// ChecksumSHA256 and ChecksumCRC32C store those checksums of every
+	// object written, next to the MD5 ETag that is always computed.
+	ChecksumSHA256 bool `json:"checksum_sha256"`
+	ChecksumCRC32C bool `json:"checksum_crc32c"`
+	// PresignKey is the secret used to sign presigned URLs. Presigning is
+	// disabled when it is empty.
+	PresignKey string `json:"presign_key"`
+	// PresignBaseURL is the URL PresignHandler is served at, for example
+	// "https://files.example.com/objex".
+	PresignBaseURL string `json:"presign_base_url"`
+	// Logger receives the driver's diagnostics. Nil discards them.
+	Logger *slog.Logger `json:"-"`
 }
 
 func (c Config) DriverName() string {
 	return driverName
 }
 
+// Validate reports a missing BasePath or an invalid Bucket.
+func (c Config) Validate() error {
+	if c.BasePath == "" {
+		return objex.ErrInvalidEndpoint
+	}
+	if c.Bucket != "" {
+		return validBucketName(c.Bucket)
+	}
+	return nil
+}
+
 type Store struct {
-	basePath string
-	bucket   string
+	basePath       string
+	bucket         string
+	presignKey     []byte
+	presignBaseURL string
+	checksumSHA256 bool
+	checksumCRC32C bool
+	locks          *keyLocks
+	logger         *slog.Logger
 }
 
 func NewStore(config Config) (*Store, error) {
-	if config.BasePath == "" {
-		return nil, objex.ErrInvalidEndpoint
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = objex.DiscardLogger()
 	}
 	return &Store{
-		basePath: config.BasePath,
+		basePath:       config.BasePath,
+		bucket:         config.Bucket,
+		presignKey:     []byte(config.PresignKey),
+		presignBaseURL: config.PresignBaseURL,
+		checksumSHA256: config.ChecksumSHA256,
+		checksumCRC32C: config.ChecksumCRC32C,
+		locks:          &keyLocks{},
+		logger:         logger.With("driver", driverName),
 	}, nil
 }
 
 func (s *Store) Setup() error {
-	return os.MkdirAll(s.basePath, 0755)
+	return s.SetupContext(context.Background())
+}
+
+func (s *Store) SetupContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return toError("Setup", "", "", os.MkdirAll(s.basePath, 0755))
 }
 
 func (s *Store) SetBucket(bucketName string) (bool, error) {
+	return s.SetBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) SetBucketContext(ctx context.Context, bucketName string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	err := s.checkBucket(bucketName)
+	if err != nil {
+		return false, toError("SetBucket", bucketName, "", err)
+	}
 	path := filepath.Join(s.basePath, bucketName)
-	err := os.MkdirAll(path, 0755)
+	err = os.MkdirAll(path, 0755)
 	if err != nil {
-		return false, err
+		return false, toError("SetBucket", bucketName, "", err)
 	}
 	s.bucket = bucketName
 	return true, nil
 }
 
+// CurrentBucket returns the bucket set by Config.Bucket or SetBucket.
+func (s *Store) CurrentBucket() string {
+	return s.bucket
+}
+
+// Bucket returns a handle bound to bucketName that shares the store's object locks.
+func (s *Store) Bucket(bucketName string) objex.BucketHandle {
+	// The fields are copied one by one: copying the whole Store would read
+	// bucket while SetBucket may be writing it.
+	return objex.NewBucketHandle(bucketName, &Store{
+		basePath:       s.basePath,
+		bucket:         bucketName,
+		presignKey:     s.presignKey,
+		presignBaseURL: s.presignBaseURL,
+		checksumSHA256: s.checksumSHA256,
+		checksumCRC32C: s.checksumCRC32C,
+		locks:          s.locks,
+		logger:         s.logger,
+	})
+}
+
 func (s *Store) SetRegion(region string) error {
+	return s.SetRegionContext(context.Background(), region)
+}
+
+func (s *Store) SetRegionContext(ctx context.Context, region string) error {
 	// Not applicable for filesystem
 	return nil
 }
 
 func (s *Store) CreateBucket(bucketName string) error {
-	return os.MkdirAll(filepath.Join(s.basePath, bucketName), 0755)
+	return s.CreateBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) CreateBucketContext(ctx context.Context, bucketName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := validBucketName(bucketName)
+	if err == nil {
+		err = s.checkLinks(bucketName, "")
+	}
+	if err == nil {
+		err = os.MkdirAll(s.basePath, 0755)
+	}
+	if err != nil {
+		return toError("CreateBucket", bucketName, "", err)
+	}
+
+	err = os.Mkdir(filepath.Join(s.basePath, bucketName), 0755)
+	if errors.Is(err, os.ErrExist) {
+		err = objex.ErrBucketAlreadyExists
+	}
+	return toError("CreateBucket", bucketName, "", err)
+}
+
+// requireBucket reports ErrBucketNotFound unless the bucket's directory
+// exists. Like S3, writing an object never creates its bucket; only the
+// root bucket, BasePath itself, is created on demand.
+func (s *Store) requireBucket(bucket string) error {
+	if bucket == rootBucket {
+		return nil
+	}
+
+	info, err := os.Stat(filepath.Join(s.basePath, bucket))
+	if errors.Is(err, os.ErrNotExist) || err == nil && !info.IsDir() {
+		return objex.ErrBucketNotFound
+	}
+	return err
 }
 
 func (s *Store) DeleteBucket(bucketName string) error {
-	return os.RemoveAll(filepath.Join(s.basePath, bucketName))
+	return s.DeleteBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) DeleteBucketContext(ctx context.Context, bucketName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := validBucketName(bucketName)
+	if err == nil {
+		err = s.checkLinks(bucketName, "")
+	}
+	if err != nil {
+		return toError("DeleteBucket", bucketName, "", err)
+	}
+
+	base := filepath.Join(s.basePath, bucketName)
+	entries, err := os.ReadDir(base)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return toError("DeleteBucket", bucketName, "", err)
+	}
+
+	// Like S3, only empty buckets can be deleted. The hidden directory
+	// only holds bookkeeping such as unfinished multipart uploads, apart
+	// from the earlier versions of objects, which count as content.
+	for _, entry := range entries {
+		if entry.Name() != hiddenDir {
+			return toError("DeleteBucket", bucketName, "", objex.ErrBucketNotEmpty)
+		}
+	}
+	versions, err := os.ReadDir(s.versionsRoot(bucketName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return toError("DeleteBucket", bucketName, "", err)
+	}
+	if len(versions) > 0 {
+		return toError("DeleteBucket", bucketName, "", objex.ErrBucketNotEmpty)
+	}
+	return toError("DeleteBucket", bucketName, "", os.RemoveAll(base))
 }
 
 func (s *Store) ListBuckets() ([]objex.Bucket, error) {
+	return s.ListBucketsContext(context.Background())
+}
+
+func (s *Store) ListBucketsContext(ctx context.Context) ([]objex.Bucket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	entries, err := os.ReadDir(s.basePath)
 	if err != nil {
-		return nil, err
+		return nil, toError("ListBuckets", "", "", err)
 	}
 
 	var buckets []objex.Bucket
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() && entry.Name() != hiddenDir {
 			info, _ := entry.Info()
 			buckets = append(buckets, objex.Bucket{
 				Name:         entry.Name(),
@@ -94,113 +272,604 @@ func (s *Store) ListBuckets() ([]objex.Bucket, error) {
 }
 
 func (s *Store) CreateObject(name string, data io.Reader, contentType string) error {
-	bucket, object, err := splitPathFS(s.bucket, name)
-	log.Printf("[%v] [%v] [%v]", bucket, object, name)
+	return s.CreateObjectContext(context.Background(), name, data, contentType)
+}
+
+func (s *Store) CreateObjectContext(ctx context.Context, name string, data io.Reader, contentType string) error {
+	return s.PutObject(ctx, name, data, objex.PutOptions{ContentType: contentType})
+}
+
+func (s *Store) PutObject(ctx context.Context, name string, data io.Reader, opts objex.PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
 	if err != nil {
 		return err
 	}
 
+	err = s.putObject(ctx, bucket, object, data, opts)
+	return toError("PutObject", bucket, object, err)
+}
+
+func (s *Store) putObject(ctx context.Context, bucket, object string, data io.Reader, opts objex.PutOptions) error {
+	defer s.locks.lock(bucket, object)()
+
+	if !opts.Conditions.IsZero() {
+		meta, err := s.statObject(bucket, object)
+		if err != nil {
+			return err
+		}
+		err = opts.Conditions.Check(meta)
+		if err != nil {
+			return err
+		}
+	}
+
 	fullPath := filepath.Join(s.basePath, bucket, object)
-	err = os.MkdirAll(filepath.Dir(fullPath), 0755)
+	outFile, err := s.createAtomic(bucket, fullPath)
 	if err != nil {
 		return err
 	}
+	defer outFile.abort()
 
-	outFile, err := os.Create(fullPath)
+	sums := s.newChecksums()
+	_, err = io.Copy(io.MultiWriter(outFile, sums.writer()), objex.ContextReader(ctx, data))
 	if err != nil {
 		return err
 	}
-	defer outFile.Close()
 
-	_, err = io.Copy(outFile, data)
-	return err
+	err = outFile.sync()
+	if err != nil {
+		return err
+	}
+
+	sc := newSidecar(opts)
+	err = sc.written(sums, outFile.Name())
+	if err != nil {
+		return err
+	}
+	return s.commitObject(bucket, object, outFile, sc)
+}
+
+// commitObject puts data, the synced new file of an object, in place along
+// with its sidecar sc, archiving the version it replaces. When it fails, the
+// object is left as it was.
+func (s *Store) commitObject(bucket, object string, data *atomicFile, sc *sidecar) error {
+	undo, err := s.replaceVersion(bucket, object, sc)
+	if err != nil {
+		return err
+	}
+
+	meta, err := s.stageSidecar(bucket, object, sc)
+	if err != nil {
+		undo()
+		return err
+	}
+	defer meta.abort()
+
+	err = s.replaceObject(bucket, object, data.Name(), meta.Name())
+	if err != nil {
+		undo()
+		return err
+	}
+	data.committed = true
+	meta.committed = true
+	return nil
+}
+
+// replaceObject renames the file at dataPath over an object and the sidecar
+// at metaPath over its sidecar, or removes its sidecar when metaPath is
+// empty. The sidecar goes first, and is put back along with the previous
+// one if the data cannot follow, so a failure leaves the object as it was.
+// In between, readers see a sidecar that does not match the file and
+// describe the file alone.
+func (s *Store) replaceObject(bucket, object, dataPath, metaPath string) error {
+	destMeta := s.sidecarPath(bucket, object)
+	previous, err := os.ReadFile(destMeta)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if metaPath != "" {
+		err = renameInto(metaPath, destMeta)
+	} else {
+		err = removeIfExists(destMeta)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = renameInto(dataPath, filepath.Join(s.basePath, bucket, object))
+	if err == nil {
+		return nil
+	}
+
+	var restoreErr error
+	if metaPath != "" {
+		restoreErr = rename(destMeta, metaPath)
+	}
+	if previous != nil {
+		restoreErr = errors.Join(restoreErr, s.restoreSidecar(bucket, object, previous))
+	}
+	return errors.Join(err, restoreErr)
 }
 
 func (s *Store) ReadObject(name string) ([]byte, error) {
-	bucket, object, err := splitPathFS(s.bucket, name)
+	return s.ReadObjectContext(context.Background(), name)
+}
+
+func (s *Store) ReadObjectContext(ctx context.Context, name string) ([]byte, error) {
+	return objex.ReadObject(ctx, s, name)
+}
+
+func (s *Store) OpenObject(ctx context.Context, name string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	bucket, object, err := s.splitPath(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	body, meta, err := s.openObject(bucket, object, opts)
+	if err != nil {
+		return nil, nil, toError("OpenObject", bucket, object, err)
+	}
+	return body, meta, nil
+}
+
+func (s *Store) openObject(bucket, object string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	return openFile(filepath.Join(s.basePath, bucket, object), opts, func(info fs.FileInfo) (*objex.ObjectMetaData, error) {
+		return s.objectMetaData(bucket, object, info)
+	})
+}
+
+// openFile opens the file at path, described by describe, checking the
+// conditions and seeking to the range in opts.
+func openFile(path string, opts objex.GetOptions, describe func(info fs.FileInfo) (*objex.ObjectMetaData, error)) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, objex.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, objex.ErrObjectNotFound
+	}
+
+	meta, err := describe(info)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	err = opts.Conditions.Check(meta)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	if opts.Range == nil {
+		return file, meta, nil
+	}
+
+	offset, length, err := opts.Range.Bounds(info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
 	}
-	return os.ReadFile(filepath.Join(s.basePath, bucket, object))
+
+	meta.Size = length
+	return rangeReader{Reader: io.LimitReader(file, length), Closer: file}, meta, nil
+}
+
+type rangeReader struct {
+	io.Reader
+	io.Closer
 }
 
 func (s *Store) UpdateObject(name string, data io.Reader) error {
-	return s.CreateObject(name, data, "")
+	return s.UpdateObjectContext(context.Background(), name, data)
+}
+
+func (s *Store) UpdateObjectContext(ctx context.Context, name string, data io.Reader) error {
+	meta, err := s.metadata(ctx, "UpdateObject", name)
+	if err != nil {
+		return err
+	}
+	return s.PutObject(ctx, name, data, meta.PutOptions())
 }
 
 func (s *Store) DeleteObject(name string) error {
-	bucket, object, err := splitPathFS(s.bucket, name)
+	return s.DeleteObjectContext(context.Background(), name)
+}
+
+func (s *Store) DeleteObjectContext(ctx context.Context, name string) error {
+	return s.DeleteObjectIf(ctx, name, objex.Conditions{})
+}
+
+func (s *Store) DeleteObjectIf(ctx context.Context, name string, cond objex.Conditions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteObject(bucket, object, cond)
+	return toError("DeleteObject", bucket, object, err)
+}
+
+func (s *Store) deleteObject(bucket, object string, cond objex.Conditions) error {
+	defer s.locks.lock(bucket, object)()
+
+	if !cond.IsZero() {
+		meta, err := s.statObject(bucket, object)
+		if err != nil {
+			return err
+		}
+		err = cond.Check(meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := s.versioningStatus(bucket)
 	if err != nil {
 		return err
 	}
-	return os.Remove(filepath.Join(s.basePath, bucket, object))
+	if status != objex.VersioningOff {
+		return s.deleteVersioned(bucket, object, status)
+	}
+
+	return s.removeObject(bucket, object)
+}
+
+// removeObject removes the file and sidecar of an object. Like S3, removing
+// a missing object succeeds.
+func (s *Store) removeObject(bucket, object string) error {
+	fullPath := filepath.Join(s.basePath, bucket, object)
+	err := os.Remove(fullPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	s.pruneDirs(bucket, filepath.Dir(fullPath))
+	return s.removeSidecar(bucket, object)
+}
+
+// pruneDirs removes dir and its parents inside the bucket while they are
+// empty. S3 has no directories, so none should outlive their last object.
+func (s *Store) pruneDirs(bucket, dir string) {
+	base := filepath.Join(s.basePath, bucket)
+	for strings.HasPrefix(dir, base+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
 }
 
 func (s *Store) ListObjects(bucket string) ([]*objex.ObjectMetaData, error) {
+	return s.ListObjectsContext(context.Background(), bucket)
+}
+
+func (s *Store) ListObjectsContext(ctx context.Context, bucket string) ([]*objex.ObjectMetaData, error) {
 	if bucket == "" {
 		bucket = s.bucket
 	}
 
-	var objects []*objex.ObjectMetaData
+	err := s.checkBucket(bucket)
+	if err == nil {
+		err = s.requireBucket(bucket)
+	}
+	if err != nil {
+		return nil, toError("ListObjects", bucket, "", err)
+	}
+	base := filepath.Join(s.basePath, bucket)
+
+	objects, err := s.walkObjects(ctx, bucket, base)
+	if err != nil {
+		return nil, toError("ListObjects", bucket, "", err)
+	}
+
+	// WalkDir visits "a/x" before "a-b"; S3 lists in key order.
+	slices.SortFunc(objects, func(a, b *objex.ObjectMetaData) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return objects, nil
+}
+
+func (s *Store) ListObjectsPage(ctx context.Context, bucket string, opts objex.ListOptions) (*objex.ListResult, error) {
+	if bucket == "" {
+		bucket = s.bucket
+	}
+
+	err := s.checkBucket(bucket)
+	if err == nil {
+		err = s.requireBucket(bucket)
+	}
+	if err != nil {
+		return nil, toError("ListObjectsPage", bucket, "", err)
+	}
+	base := filepath.Join(s.basePath, bucket)
+
+	// Only walk the directory the prefix points into. No valid key has a
+	// ".." segment, and walking one would leave the bucket.
+	dir := path.Dir(opts.Prefix)
+	if strings.HasSuffix(opts.Prefix, "/") {
+		dir = opts.Prefix
+	}
+	if slices.Contains(strings.Split(dir, "/"), "..") {
+		return &objex.ListResult{}, nil
+	}
+	root := filepath.Join(base, filepath.FromSlash(dir))
+
+	objects, err := s.walkObjects(ctx, bucket, root)
+	if errors.Is(err, os.ErrNotExist) {
+		return &objex.ListResult{}, nil
+	}
+	if err != nil {
+		return nil, toError("ListObjectsPage", bucket, "", err)
+	}
+
+	return objex.PageObjects(objects, opts), nil
+}
+
+func (s *Store) walkObjects(ctx context.Context, bucket, root string) ([]*objex.ObjectMetaData, error) {
 	base := filepath.Join(s.basePath, bucket)
+	var objects []*objex.ObjectMetaData
 
-	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == hiddenDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 		info, _ := d.Info()
 		relative, _ := filepath.Rel(base, path)
 
-		objects = append(objects, &objex.ObjectMetaData{
-			Key:          relative,
-			Size:         info.Size(),
-			ContentType:  "application/octet-stream", // Simplified
-			ETag:         "",                         // Not used
-			LastModified: info.ModTime().Format(time.RFC3339),
-		})
+		meta, err := s.objectMetaData(bucket, filepath.ToSlash(relative), info)
+		if err != nil {
+			return err
+		}
+		meta.UserMetadata = nil
+		objects = append(objects, meta)
 		return nil
 	})
 	return objects, err
 }
 
+func (s *Store) Objects(ctx context.Context, bucket string, opts objex.ListOptions) iter.Seq2[*objex.ObjectMetaData, error] {
+	return func(yield func(*objex.ObjectMetaData, error) bool) {
+		if bucket == "" {
+			bucket = s.bucket
+		}
+
+		err := s.checkBucket(bucket)
+		if err == nil {
+			err = s.requireBucket(bucket)
+		}
+		if err != nil {
+			yield(nil, toError("Objects", bucket, "", err))
+			return
+		}
+
+		s.walkSorted(ctx, bucket, "", opts.Prefix, max(opts.StartAfter, opts.ContinuationToken), func(meta *objex.ObjectMetaData, err error) bool {
+			return yield(meta, toError("Objects", bucket, "", err))
+		})
+	}
+}
+
+// walkSorted yields the files below the key prefix dir in S3 key order.
+// filepath.WalkDir visits "a/x" before "a-b", so entries are sorted with a
+// trailing slash on directory names instead. Subtrees that cannot hold a key
+// matching prefix or sorting after after are skipped. It returns false once
+// yield asks to stop.
+func (s *Store) walkSorted(ctx context.Context, bucket, dir, prefix, after string, yield func(*objex.ObjectMetaData, error) bool) bool {
+	entries, err := os.ReadDir(filepath.Join(s.basePath, bucket, filepath.FromSlash(dir)))
+	if err != nil {
+		return yield(nil, err)
+	}
+
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = dir + entry.Name()
+		if entry.IsDir() {
+			keys[i] += "/"
+		}
+	}
+	sort.Sort(byKey{keys, entries})
+
+	for i, entry := range entries {
+		key := keys[i]
+
+		if entry.IsDir() {
+			if entry.Name() == hiddenDir {
+				continue
+			}
+			if !strings.HasPrefix(key, prefix) && !strings.HasPrefix(prefix, key) {
+				continue
+			}
+			if key <= after && !strings.HasPrefix(after, key) {
+				continue
+			}
+			if !s.walkSorted(ctx, bucket, key, prefix, after, yield) {
+				return false
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return false
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if !yield(nil, err) {
+				return false
+			}
+			continue
+		}
+
+		meta, err := s.objectMetaData(bucket, key, info)
+		if err != nil {
+			if !yield(nil, err) {
+				return false
+			}
+			continue
+		}
+		meta.UserMetadata = nil
+
+		if !yield(meta, nil) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type byKey struct {
+	keys    []string
+	entries []fs.DirEntry
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.entries[i], b.entries[j] = b.entries[j], b.entries[i]
+}
+
+// fileMetaData describes a file without its sidecar. The content type is
+// guessed from the extension and the ETag is derived from the file's
+// modification time and size, so it still changes when the file does.
+func fileMetaData(key string, info fs.FileInfo) *objex.ObjectMetaData {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &objex.ObjectMetaData{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentType,
+		ETag:         fileETag(info),
+		LastModified: info.ModTime().Format(time.RFC3339),
+	}
+}
+
+func fileETag(info fs.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
+
 func (s *Store) Exists(name string) (bool, *objex.ObjectMetaData, error) {
-	bucket, object, err := splitPathFS(s.bucket, name)
+	return s.ExistsContext(context.Background(), name)
+}
+
+func (s *Store) ExistsContext(ctx context.Context, name string) (bool, *objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+	bucket, object, err := s.splitPath(name)
 	if err != nil {
 		return false, nil, err
 	}
-	path := filepath.Join(s.basePath, bucket, object)
-	info, err := os.Stat(path)
-	if errors.Is(err, os.ErrNotExist) {
-		return false, nil, nil
+	meta, err := s.statObject(bucket, object)
+	if err != nil {
+		return false, nil, toError("Exists", bucket, object, err)
+	}
+	return meta != nil, meta, nil
+}
+
+// statObject returns the metadata of an object, or nil if it does not exist.
+func (s *Store) statObject(bucket, object string) (*objex.ObjectMetaData, error) {
+	info, err := os.Stat(filepath.Join(s.basePath, bucket, object))
+	if errors.Is(err, os.ErrNotExist) || err == nil && info.IsDir() {
+		// A directory is only the common prefix of other keys.
+		return nil, nil
 	}
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
-	return true, &objex.ObjectMetaData{
-		Key:          object,
-		Size:         info.Size(),
-		LastModified: info.ModTime().Format(time.RFC3339),
-		ContentType:  "application/octet-stream",
-	}, nil
+
+	return s.objectMetaData(bucket, object, info)
 }
 
 func (s *Store) Metadata(name string) (*objex.ObjectMetaData, error) {
-	found, meta, err := s.Exists(name)
+	return s.MetadataContext(context.Background(), name)
+}
+
+func (s *Store) MetadataContext(ctx context.Context, name string) (*objex.ObjectMetaData, error) {
+	return s.metadata(ctx, "Metadata", name)
+}
+
+// metadata returns the metadata of an existing object, reporting
+// ErrObjectNotFound as a failure of op.
+func (s *Store) metadata(ctx context.Context, op, name string) (*objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bucket, object, err := s.splitPath(name)
 	if err != nil {
 		return nil, err
 	}
-	if !found {
-		return nil, objex.ErrObjectNotFound
+
+	meta, err := s.statObject(bucket, object)
+	if err == nil && meta == nil {
+		err = objex.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, toError(op, bucket, object, err)
 	}
 	return meta, nil
 }
 
 func (s *Store) CopyObject(src, dest string) error {
-	srcBucket, srcObject, err := splitPathFS(s.bucket, src)
+	return s.CopyObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) CopyObjectContext(ctx context.Context, src, dest string) error {
+	return s.CopyObjectIf(ctx, src, dest, objex.CopyConditions{})
+}
+
+func (s *Store) CopyObjectIf(ctx context.Context, src, dest string, cond objex.CopyConditions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	srcBucket, srcObject, err := s.splitPath(src)
 	if err != nil {
 		return err
 	}
-	destBucket, destObject, err := splitPathFS(s.bucket, dest)
+	destBucket, destObject, err := s.splitPath(dest)
 	if err != nil {
 		return err
 	}
@@ -208,45 +877,183 @@ func (s *Store) CopyObject(src, dest string) error {
 	srcPath := filepath.Join(s.basePath, srcBucket, srcObject)
 	destPath := filepath.Join(s.basePath, destBucket, destObject)
 
-	err = os.MkdirAll(filepath.Dir(destPath), 0755)
-	if err != nil {
-		return err
-	}
+	defer s.locks.lock(srcBucket, srcObject, destBucket, destObject)()
 
 	srcFile, err := os.Open(srcPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return toError("CopyObject", srcBucket, srcObject, objex.ErrObjectNotFound)
+	}
 	if err != nil {
-		return err
+		return toError("CopyObject", srcBucket, srcObject, err)
 	}
 	defer srcFile.Close()
 
-	destFile, err := os.Create(destPath)
+	srcInfo, err := srcFile.Stat()
 	if err != nil {
-		return err
+		return toError("CopyObject", srcBucket, srcObject, err)
+	}
+	if srcInfo.IsDir() {
+		return toError("CopyObject", srcBucket, srcObject, objex.ErrObjectNotFound)
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, srcFile)
-	return err
+	sc, err := s.readSidecar(srcBucket, srcObject)
+	if err != nil {
+		return toError("CopyObject", srcBucket, srcObject, err)
+	}
+
+	if !cond.Source.IsZero() {
+		meta := fileMetaData(srcObject, srcInfo)
+		sc.apply(meta, srcInfo)
+
+		err = cond.Source.Check(meta)
+		if err != nil {
+			return toError("CopyObject", srcBucket, srcObject, err)
+		}
+	}
+
+	if !cond.Destination.IsZero() {
+		meta, err := s.statObject(destBucket, destObject)
+		if err != nil {
+			return toError("CopyObject", srcBucket, srcObject, err)
+		}
+		err = cond.Destination.Check(meta)
+		if err != nil {
+			return toError("CopyObject", srcBucket, srcObject, err)
+		}
+	}
+
+	destFile, err := s.createAtomic(destBucket, destPath)
+	if err != nil {
+		return toError("CopyObject", srcBucket, srcObject, err)
+	}
+	defer destFile.abort()
+
+	sums := s.newChecksums()
+	_, err = io.Copy(io.MultiWriter(destFile, sums.writer()), objex.ContextReader(ctx, srcFile))
+	if err != nil {
+		return toError("CopyObject", srcBucket, srcObject, err)
+	}
+
+	err = destFile.sync()
+	if err == nil {
+		err = sc.written(sums, destFile.Name())
+	}
+	if err == nil {
+		err = s.commitObject(destBucket, destObject, destFile, sc)
+	}
+	return toError("CopyObject", srcBucket, srcObject, err)
 }
 
 func (s *Store) MoveObject(src, dest string) error {
-	err := s.CopyObject(src, dest)
+	return s.MoveObjectContext(context.Background(), src, dest)
+}
+
+// MoveObjectContext renames the object and its metadata in one step, so it
+// keeps its ETag and upload time and is never seen at both keys. In
+// versioned buckets it copies and deletes instead, so both keys keep their
+// history.
+func (s *Store) MoveObjectContext(ctx context.Context, src, dest string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	srcBucket, srcObject, err := s.splitPath(src)
+	if err != nil {
+		return err
+	}
+	destBucket, destObject, err := s.splitPath(dest)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range []string{srcBucket, destBucket} {
+		status, err := s.versioningStatus(bucket)
+		if err != nil {
+			return toError("MoveObject", srcBucket, srcObject, err)
+		}
+		if status != objex.VersioningOff {
+			err = s.CopyObjectContext(ctx, src, dest)
+			if err != nil {
+				return err
+			}
+			return s.DeleteObjectContext(ctx, src)
+		}
+	}
+
+	err = s.moveObject(srcBucket, srcObject, destBucket, destObject)
+	return toError("MoveObject", srcBucket, srcObject, err)
+}
+
+func (s *Store) moveObject(srcBucket, srcObject, destBucket, destObject string) error {
+	defer s.locks.lock(srcBucket, srcObject, destBucket, destObject)()
+
+	srcPath := filepath.Join(s.basePath, srcBucket, srcObject)
+	info, err := os.Stat(srcPath)
+	if errors.Is(err, os.ErrNotExist) || err == nil && info.IsDir() {
+		return objex.ErrObjectNotFound
+	}
 	if err != nil {
 		return err
 	}
-	return s.DeleteObject(src)
+	if srcBucket == destBucket && srcObject == destObject {
+		return nil
+	}
+	err = s.requireBucket(destBucket)
+	if err != nil {
+		return err
+	}
+
+	// The renamed file keeps its size and modification time, so its
+	// sidecar, if it has one, moves with it and still describes it.
+	srcMeta := s.sidecarPath(srcBucket, srcObject)
+	_, err = os.Stat(srcMeta)
+	if errors.Is(err, os.ErrNotExist) {
+		srcMeta = ""
+	} else if err != nil {
+		return err
+	}
+
+	err = s.replaceObject(destBucket, destObject, srcPath, srcMeta)
+	if err != nil {
+		return err
+	}
+
+	s.pruneDirs(srcBucket, filepath.Dir(srcPath))
+	return nil
 }
 
 func (s *Store) CleanUp() error {
-	log.Println("[Objex Filesystem] CleanUp called — no action needed")
+	return s.CleanUpContext(context.Background())
+}
+
+func (s *Store) CleanUpContext(ctx context.Context) error {
+	s.logger.DebugContext(ctx, "nothing to clean up")
 	return nil
 }
 
+// Capabilities reports presigning only when Config.PresignKey and
+// PresignBaseURL are set.
+func (s *Store) Capabilities() objex.Capabilities {
+	return objex.Capabilities{
+		Presign:        len(s.presignKey) > 0 && s.presignBaseURL != "",
+		Multipart:      true,
+		ServerSideCopy: true,
+		AllConditions:  true,
+		Versioning:     true,
+	}
+}
+
 func (s *Store) HealthCheck() error {
+	return s.HealthCheckContext(context.Background())
+}
+
+func (s *Store) HealthCheckContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if s.basePath == "" {
 		return objex.ErrInvalidEndpoint
 	}
-	return os.MkdirAll(s.basePath, 0755)
+	return toError("HealthCheck", "", "", os.MkdirAll(s.basePath, 0755))
 }
 
 func splitPathFS(bucket, name string) (string, string, error) {
@@ -264,5 +1071,5 @@ func splitPathFS(bucket, name string) (string, string, error) {
 	}
 
 	// No bucket set, no slash in name — treat as root bucket
-	return ".", name, nil
+	return rootBucket, name, nil
 }
@@ -0,0 +1,110 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/filesystem"
+	"github.com/brian-nunez/objex/objextest"
+)
+
+func TestConformance(t *testing.T) {
+	objextest.Run(t, func(t *testing.T) objex.Store {
+		return newPresigningStore(t)
+	})
+}
+
+// newPresigningStore returns a store whose presigned URLs are served by a
+// test server until the test ends.
+func newPresigningStore(t *testing.T) *filesystem.Store {
+	t.Helper()
+
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := filesystem.NewStore(filesystem.Config{
+		BasePath:       t.TempDir(),
+		PresignKey:     "secret",
+		PresignBaseURL: server.URL + "/objex",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = store.PresignHandler()
+	return store
+}
+
+// Sidecars used to live at <key>.json, where the sidecar of "a" was a file
+// and that of "a.json/x" needed it to be a directory.
+func TestSidecarKeysDoNotCollide(t *testing.T) {
+	for _, keys := range [][]string{{"a", "a.json/x"}, {"a.json/x", "a"}} {
+		t.Run(strings.Join(keys, ","), func(t *testing.T) {
+			store, err := filesystem.NewStore(filesystem.Config{BasePath: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.CreateBucket("docs")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, key := range keys {
+				err = store.PutObject(context.Background(), "docs/"+key, strings.NewReader(key), objex.PutOptions{ContentType: "text/" + key})
+				if err != nil {
+					t.Fatalf("PutObject %q: %v", key, err)
+				}
+			}
+
+			for _, key := range keys {
+				meta, err := store.Metadata("docs/" + key)
+				if err != nil {
+					t.Fatalf("Metadata %q: %v", key, err)
+				}
+				if meta.ContentType != "text/"+key {
+					t.Errorf("Metadata %q: got content type %q, want %q", key, meta.ContentType, "text/"+key)
+				}
+			}
+		})
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    filesystem.Config
+		wantErr error
+	}{
+		{url: "file:///var/data/photos", want: filesystem.Config{BasePath: "/var/data", Bucket: "photos"}},
+		{url: "file:///var/data/", want: filesystem.Config{BasePath: "/var/data"}},
+		{url: "file://localhost/var/data/photos", want: filesystem.Config{BasePath: "/var/data", Bucket: "photos"}},
+		{
+			url:  "file:///var/data/photos?sha256=true&crc32c=1",
+			want: filesystem.Config{BasePath: "/var/data", Bucket: "photos", ChecksumSHA256: true, ChecksumCRC32C: true},
+		},
+		{url: "file://example.com/var/data/photos", wantErr: objex.ErrInvalidEndpoint},
+		{url: "file:", wantErr: objex.ErrInvalidEndpoint},
+		{url: "file:///var/data/photos?sha256=maybe", wantErr: objex.ErrInvalidEndpoint},
+		{url: "file:///var/data/photos?md5=true", wantErr: objex.ErrInvalidEndpoint},
+	}
+
+	for _, test := range tests {
+		config, err := objex.ParseURL(test.url)
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("ParseURL(%s): got error %v, want %v", test.url, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil || config != test.want {
+			t.Errorf("ParseURL(%s): got %+v, %v, want %+v", test.url, config, err, test.want)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/brian-nunez/objex/drivers/filesystem
 
-go 1.22.2
+go 1.23.0
 
 require github.com/brian-nunez/objex v1.0.3
 
@@ -0,0 +1,62 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// keyLocks serializes writers of the same object within a Store, so a
+// conditional operation can check the object and change it without another
+// write slipping in between. It does not guard against other processes.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the objects named by bucket/object pairs in a fixed order and
+// returns the function that unlocks them.
+func (l *keyLocks) lock(paths ...string) func() {
+	keys := make([]string, 0, len(paths)/2)
+	for i := 0; i+1 < len(paths); i += 2 {
+		keys = append(keys, filepath.Join(paths[i], paths[i+1]))
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	held := make([]*keyLock, len(keys))
+	for i, key := range keys {
+		l.mu.Lock()
+		if l.locks == nil {
+			l.locks = make(map[string]*keyLock)
+		}
+		lock, ok := l.locks[key]
+		if !ok {
+			lock = &keyLock{}
+			l.locks[key] = lock
+		}
+		lock.refs++
+		l.mu.Unlock()
+
+		lock.Lock()
+		held[i] = lock
+	}
+
+	return func() {
+		for i := len(keys) - 1; i >= 0; i-- {
+			held[i].Unlock()
+
+			l.mu.Lock()
+			held[i].refs--
+			if held[i].refs == 0 {
+				delete(l.locks, keys[i])
+			}
+			l.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+// hiddenDir holds the driver's own bookkeeping inside each bucket. It is
+// skipped when listing buckets and objects.
+const hiddenDir = ".objex"
+
+// sidecar is the metadata persisted next to an object, in
+// <bucket>/.objex/meta/<sha256 of key>.json.
+type sidecar struct {
+	ContentType        string            `json:"content_type,omitempty"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	ContentEncoding    string            `json:"content_encoding,omitempty"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	ContentLanguage    string            `json:"content_language,omitempty"`
+	UserMetadata       map[string]string `json:"user_metadata,omitempty"`
+	// ETag, the checksums and Uploaded, the Unix time in nanoseconds the
+	// object was written, are recorded when the driver writes the file.
+	// Size and ModTime identify that version of it, so the whole sidecar is
+	// ignored once the file has been changed by something else.
+	ETag           string `json:"etag,omitempty"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+	ChecksumCRC32C string `json:"checksum_crc32c,omitempty"`
+	Uploaded       int64  `json:"uploaded,omitempty"`
+	// VersionID is empty for the "null" version written while versioning
+	// was off or suspended.
+	VersionID string `json:"version_id,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	ModTime   int64  `json:"mod_time,omitempty"`
+}
+
+func newSidecar(opts objex.PutOptions) *sidecar {
+	return &sidecar{
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		ContentEncoding:    opts.ContentEncoding,
+		CacheControl:       opts.CacheControl,
+		ContentLanguage:    opts.ContentLanguage,
+		UserMetadata:       objex.NormalizeUserMetadata(opts.UserMetadata),
+	}
+}
+
+func (sc *sidecar) empty() bool {
+	return sc.ContentType == "" &&
+		sc.ContentDisposition == "" &&
+		sc.ContentEncoding == "" &&
+		sc.CacheControl == "" &&
+		sc.ContentLanguage == "" &&
+		len(sc.UserMetadata) == 0 &&
+		sc.ETag == "" &&
+		sc.VersionID == ""
+}
+
+// stale reports whether the file described by info is not the version sc
+// was written for. Sidecars from before ETags were recorded are never
+// stale.
+func (sc *sidecar) stale(info fs.FileInfo) bool {
+	return sc.ETag != "" && (sc.Size != info.Size() || sc.ModTime != info.ModTime().UnixNano())
+}
+
+// apply fills meta, which describes the file info, with what sc records,
+// unless sc is stale.
+func (sc *sidecar) apply(meta *objex.ObjectMetaData, info fs.FileInfo) {
+	if sc.stale(info) {
+		return
+	}
+
+	if sc.ContentType != "" {
+		meta.ContentType = sc.ContentType
+	}
+	meta.ContentDisposition = sc.ContentDisposition
+	meta.ContentEncoding = sc.ContentEncoding
+	meta.CacheControl = sc.CacheControl
+	meta.ContentLanguage = sc.ContentLanguage
+	meta.UserMetadata = sc.UserMetadata
+
+	if sc.ETag != "" {
+		meta.ETag = sc.ETag
+		meta.ChecksumSHA256 = sc.ChecksumSHA256
+		meta.ChecksumCRC32C = sc.ChecksumCRC32C
+	}
+	if sc.Uploaded != 0 {
+		meta.LastModified = time.Unix(0, sc.Uploaded).Format(time.RFC3339)
+	}
+	if sc.VersionID != "" {
+		meta.VersionID = sc.VersionID
+	}
+}
+
+// written records the checksums, upload time and version of the file the
+// driver just wrote for sc's object.
+func (sc *sidecar) written(sums *checksums, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	sums.store(sc)
+	sc.Uploaded = time.Now().UnixNano()
+	sc.Size = info.Size()
+	sc.ModTime = info.ModTime().UnixNano()
+	return nil
+}
+
+func (sc *sidecar) putOptions() objex.PutOptions {
+	return objex.PutOptions{
+		ContentType:        sc.ContentType,
+		ContentDisposition: sc.ContentDisposition,
+		ContentEncoding:    sc.ContentEncoding,
+		CacheControl:       sc.CacheControl,
+		ContentLanguage:    sc.ContentLanguage,
+		UserMetadata:       sc.UserMetadata,
+	}
+}
+
+// objectMetaData describes object from its file info and sidecar.
+func (s *Store) objectMetaData(bucket, object string, info fs.FileInfo) (*objex.ObjectMetaData, error) {
+	sc, err := s.readSidecar(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := fileMetaData(object, info)
+	sc.apply(meta, info)
+	return meta, nil
+}
+
+// sidecarPath names sidecars after a hash of the key, as versionsDir does,
+// so that keys such as "a" and "a.json/x" cannot collide.
+func (s *Store) sidecarPath(bucket, object string) string {
+	sum := sha256.Sum256([]byte(object))
+	return filepath.Join(s.basePath, bucket, hiddenDir, "meta", hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *Store) writeSidecar(bucket, object string, sc *sidecar) error {
+	if sc.empty() {
+		return s.removeSidecar(bucket, object)
+	}
+
+	file, err := s.stageSidecar(bucket, object, sc)
+	if err != nil {
+		return err
+	}
+	defer file.abort()
+	return file.commit()
+}
+
+// stageSidecar writes sc to a temporary file that commit renames over the
+// object's sidecar, so the sidecar can be written in full before the object
+// it describes is replaced.
+func (s *Store) stageSidecar(bucket, object string, sc *sidecar) (*atomicFile, error) {
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.createAtomic(bucket, s.sidecarPath(bucket, object))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.sync()
+	}
+	if err != nil {
+		file.abort()
+		return nil, err
+	}
+	return file, nil
+}
+
+// restoreSidecar writes back data, the sidecar an object had before a
+// failed replaceObject.
+func (s *Store) restoreSidecar(bucket, object string, data []byte) error {
+	file, err := s.createAtomic(bucket, s.sidecarPath(bucket, object))
+	if err != nil {
+		return err
+	}
+	defer file.abort()
+
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+	return file.commit()
+}
+
+// readSidecar returns the metadata stored for an object, or an empty
+// sidecar if there is none.
+func (s *Store) readSidecar(bucket, object string) (*sidecar, error) {
+	sc := &sidecar{}
+
+	data, err := os.ReadFile(s.sidecarPath(bucket, object))
+	if errors.Is(err, os.ErrNotExist) {
+		return sc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+// removeSidecar removes the metadata of an object.
+func (s *Store) removeSidecar(bucket, object string) error {
+	return removeIfExists(s.sidecarPath(bucket, object))
+}
+
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,358 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+const maxPartNumber = 10000
+
+// upload is stored as upload.json in the staging directory of a multipart
+// upload, <bucket>/.objex/uploads/<id>/. Each part is staged next to it in a
+// file named <part number>.<md5>, so listing parts does not re-read them.
+type upload struct {
+	Key       string   `json:"key"`
+	Initiated string   `json:"initiated"`
+	Metadata  *sidecar `json:"metadata,omitempty"`
+}
+
+func (s *Store) uploadsDir(bucket string) string {
+	return filepath.Join(s.basePath, bucket, hiddenDir, "uploads")
+}
+
+func (s *Store) CreateMultipartUpload(ctx context.Context, name string, opts objex.PutOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return "", err
+	}
+	err = s.requireBucket(bucket)
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, object, err)
+	}
+
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, object, err)
+	}
+	uploadID := hex.EncodeToString(id)
+
+	data, err := json.Marshal(upload{
+		Key:       object,
+		Initiated: time.Now().Format(time.RFC3339),
+		Metadata:  newSidecar(opts),
+	})
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, object, err)
+	}
+
+	dir := filepath.Join(s.uploadsDir(bucket), uploadID)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, object, err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "upload.json"), data, 0644)
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucket, object, err)
+	}
+
+	return uploadID, nil
+}
+
+// loadUpload returns the staging directory of an upload after checking that
+// it exists and belongs to object.
+func (s *Store) loadUpload(bucket, object, uploadID string) (string, *upload, error) {
+	id, err := hex.DecodeString(uploadID)
+	if err != nil || len(id) != 16 {
+		return "", nil, objex.ErrUploadNotFound
+	}
+
+	dir := filepath.Join(s.uploadsDir(bucket), uploadID)
+	data, err := os.ReadFile(filepath.Join(dir, "upload.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil, objex.ErrUploadNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	u := &upload{}
+	err = json.Unmarshal(data, u)
+	if err != nil {
+		return "", nil, err
+	}
+	if u.Key != object {
+		return "", nil, objex.ErrUploadNotFound
+	}
+
+	return dir, u, nil
+}
+
+func (s *Store) UploadPart(ctx context.Context, name, uploadID string, partNumber int, data io.Reader) (objex.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return objex.Part{}, err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return objex.Part{}, err
+	}
+	if partNumber < 1 || partNumber > maxPartNumber {
+		return objex.Part{}, toError("UploadPart", bucket, object, objex.ErrInvalidPart)
+	}
+
+	dir, _, err := s.loadUpload(bucket, object, uploadID)
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "tmp-")
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), objex.ContextReader(ctx, data))
+	closeErr := tmp.Close()
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, err)
+	}
+	if closeErr != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, closeErr)
+	}
+
+	// Uploading a part number again replaces the earlier part.
+	parts, err := listStagedParts(dir)
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, err)
+	}
+	for _, part := range parts {
+		if part.PartNumber == partNumber {
+			err := removeIfExists(filepath.Join(dir, partFileName(part)))
+			if err != nil {
+				s.logger.WarnContext(ctx, "removing replaced part",
+					"bucket", bucket, "upload", uploadID, "part", partNumber, "error", err)
+			}
+		}
+	}
+
+	part := objex.Part{
+		PartNumber:   partNumber,
+		ETag:         hex.EncodeToString(hash.Sum(nil)),
+		Size:         size,
+		LastModified: time.Now().Format(time.RFC3339),
+	}
+
+	err = os.Rename(tmp.Name(), filepath.Join(dir, partFileName(part)))
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucket, object, err)
+	}
+
+	return part, nil
+}
+
+func partFileName(part objex.Part) string {
+	return fmt.Sprintf("%05d.%s", part.PartNumber, part.ETag)
+}
+
+func listStagedParts(dir string) ([]objex.Part, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []objex.Part
+	for _, entry := range entries {
+		number, etag, ok := strings.Cut(entry.Name(), ".")
+		partNumber, err := strconv.Atoi(number)
+		if !ok || err != nil || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, objex.Part{
+			PartNumber:   partNumber,
+			ETag:         etag,
+			Size:         info.Size(),
+			LastModified: info.ModTime().Format(time.RFC3339),
+		})
+	}
+
+	slices.SortFunc(parts, func(a, b objex.Part) int {
+		return a.PartNumber - b.PartNumber
+	})
+	return parts, nil
+}
+
+func (s *Store) ListParts(ctx context.Context, name, uploadID string) ([]objex.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, _, err := s.loadUpload(bucket, object, uploadID)
+	if err != nil {
+		return nil, toError("ListParts", bucket, object, err)
+	}
+
+	parts, err := listStagedParts(dir)
+	if err != nil {
+		return nil, toError("ListParts", bucket, object, err)
+	}
+	return parts, nil
+}
+
+func (s *Store) CompleteMultipartUpload(ctx context.Context, name, uploadID string, parts []objex.Part) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return err
+	}
+
+	dir, u, err := s.loadUpload(bucket, object, uploadID)
+	if err != nil {
+		return toError("CompleteMultipartUpload", bucket, object, err)
+	}
+
+	staged, err := listStagedParts(dir)
+	if err != nil {
+		return toError("CompleteMultipartUpload", bucket, object, err)
+	}
+
+	etags := make(map[int]string, len(staged))
+	for _, part := range staged {
+		etags[part.PartNumber] = part.ETag
+	}
+
+	// Like S3's InvalidPartOrder, parts must be listed once each, in
+	// ascending order.
+	if len(parts) == 0 {
+		return toError("CompleteMultipartUpload", bucket, object, objex.ErrInvalidPart)
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return toError("CompleteMultipartUpload", bucket, object, objex.ErrInvalidPart)
+		}
+
+		etag, ok := etags[part.PartNumber]
+		if !ok || etag != strings.Trim(part.ETag, `"`) {
+			return toError("CompleteMultipartUpload", bucket, object, objex.ErrInvalidPart)
+		}
+
+		file, err := os.Open(filepath.Join(dir, partFileName(objex.Part{PartNumber: part.PartNumber, ETag: etag})))
+		if err != nil {
+			return toError("CompleteMultipartUpload", bucket, object, err)
+		}
+		defer file.Close()
+
+		readers = append(readers, file)
+	}
+
+	opts := objex.PutOptions{}
+	if u.Metadata != nil {
+		opts = u.Metadata.putOptions()
+	}
+
+	err = s.putObject(ctx, bucket, object, io.MultiReader(readers...), opts)
+	if err != nil {
+		return toError("CompleteMultipartUpload", bucket, object, err)
+	}
+
+	return toError("CompleteMultipartUpload", bucket, object, os.RemoveAll(dir))
+}
+
+func (s *Store) AbortMultipartUpload(ctx context.Context, name, uploadID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return err
+	}
+
+	dir, _, err := s.loadUpload(bucket, object, uploadID)
+	if err != nil {
+		return toError("AbortMultipartUpload", bucket, object, err)
+	}
+
+	return toError("AbortMultipartUpload", bucket, object, os.RemoveAll(dir))
+}
+
+func (s *Store) ListMultipartUploads(ctx context.Context, bucket, prefix string) ([]objex.MultipartUpload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		bucket = s.bucket
+	}
+	err := s.checkBucket(bucket)
+	if err != nil {
+		return nil, toError("ListMultipartUploads", bucket, "", err)
+	}
+
+	entries, err := os.ReadDir(s.uploadsDir(bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toError("ListMultipartUploads", bucket, "", err)
+	}
+
+	var uploads []objex.MultipartUpload
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(s.uploadsDir(bucket), entry.Name(), "upload.json"))
+		if err != nil {
+			s.logger.DebugContext(ctx, "skipping unreadable upload",
+				"bucket", bucket, "upload", entry.Name(), "error", err)
+			continue
+		}
+
+		u := &upload{}
+		if json.Unmarshal(data, u) != nil || !strings.HasPrefix(u.Key, prefix) {
+			continue
+		}
+
+		uploads = append(uploads, objex.MultipartUpload{
+			Key:       u.Key,
+			UploadID:  entry.Name(),
+			Initiated: u.Initiated,
+		})
+	}
+
+	slices.SortFunc(uploads, func(a, b objex.MultipartUpload) int {
+		if a.Key != b.Key {
+			return strings.Compare(a.Key, b.Key)
+		}
+		return strings.Compare(a.Initiated, b.Initiated)
+	})
+	return uploads, nil
+}
@@ -0,0 +1,147 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brian-nunez/objex"
+)
+
+// rootBucket holds the objects written while no bucket is set, directly in
+// BasePath.
+const rootBucket = "."
+
+// validBucketName reports ErrInvalidBucketName unless name follows the S3
+// naming rules: 3 to 63 lower case letters, digits, dots and hyphens,
+// starting and ending with a letter or digit, without adjacent dots and not
+// formatted as an IP address. Such a name is always a single directory
+// inside BasePath.
+func validBucketName(name string) error {
+	if len(name) < 3 || len(name) > 63 || net.ParseIP(name) != nil {
+		return objex.ErrInvalidBucketName
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		case c == '-' || c == '.':
+			if i == 0 || i == len(name)-1 || c == '.' && name[i-1] == '.' {
+				return objex.ErrInvalidBucketName
+			}
+		default:
+			return objex.ErrInvalidBucketName
+		}
+	}
+	return nil
+}
+
+// validObjectName reports ErrInvalidObjectName for keys that could leave
+// their bucket or that a file system cannot hold as a file of the same
+// name: empty, longer than S3's 1024 bytes, not UTF-8, holding a NUL byte,
+// absolute, or with an empty, "." or ".." segment. No segment can name the
+// driver's hidden directory either, since listings skip it at any depth.
+func validObjectName(key string) error {
+	if key == "" || len(key) > 1024 || !utf8.ValidString(key) || strings.ContainsRune(key, 0) {
+		return objex.ErrInvalidObjectName
+	}
+
+	native := filepath.FromSlash(key)
+	if filepath.IsAbs(native) || filepath.VolumeName(native) != "" {
+		return objex.ErrInvalidObjectName
+	}
+	if filepath.Separator != '/' && strings.ContainsRune(key, filepath.Separator) {
+		return objex.ErrInvalidObjectName
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || segment == hiddenDir {
+			return objex.ErrInvalidObjectName
+		}
+	}
+	return nil
+}
+
+// splitPath returns the bucket and key name refers to, as splitPathFS
+// does, after checking both names and that no symbolic link leads the
+// object's path out of BasePath.
+func (s *Store) splitPath(name string) (bucket, object string, err error) {
+	bucket, object, err = splitPathFS(s.bucket, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if bucket != rootBucket {
+		err = validBucketName(bucket)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	err = validObjectName(object)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.checkLinks(bucket, object)
+	if err != nil {
+		return "", "", err
+	}
+	return bucket, object, nil
+}
+
+// checkBucket checks a bucket name given to a bucket-level call. The empty
+// name, which stands for BasePath itself, is allowed.
+func (s *Store) checkBucket(bucket string) error {
+	if bucket == "" {
+		return nil
+	}
+
+	err := validBucketName(bucket)
+	if err != nil {
+		return err
+	}
+	return s.checkLinks(bucket, "")
+}
+
+// checkLinks reports ErrInvalidObjectName, or ErrInvalidBucketName for an
+// empty object, when the deepest existing part of the object's path
+// resolves, through symbolic links, to somewhere outside BasePath. Links
+// that stay inside are allowed. The check races with changes to the tree
+// made outside objex, so it guards against mistakes and planted links
+// rather than a hostile local user.
+func (s *Store) checkLinks(bucket, object string) error {
+	root, err := filepath.EvalSymlinks(s.basePath)
+	if err != nil {
+		// Nothing can be reached through BasePath before it exists.
+		return nil
+	}
+
+	path := filepath.Join(s.basePath, bucket, filepath.FromSlash(object))
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+				if object == "" {
+					return objex.ErrInvalidBucketName
+				}
+				return objex.ErrInvalidObjectName
+			}
+			return nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			// Left for the operation itself to report.
+			return nil
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return nil
+		}
+		path = parent
+	}
+}
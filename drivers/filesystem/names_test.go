@@ -0,0 +1,50 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func FuzzValidObjectName(f *testing.F) {
+	for _, key := range []string{
+		"a.txt",
+		"a/b/c.txt",
+		"dir with space/ü.txt",
+		"../escape.txt",
+		"a/../../escape.txt",
+		"/absolute.txt",
+		"a//b.txt",
+		"./dot.txt",
+		"dir/",
+		".objex/meta/a.json",
+		"a/.objex/x",
+		"a\x00b",
+		"\xff",
+	} {
+		f.Add(key)
+	}
+
+	f.Fuzz(func(t *testing.T, key string) {
+		if validObjectName(key) != nil {
+			return
+		}
+
+		// An accepted key is a path strictly inside the bucket, outside the
+		// hidden directory, that names the same file it was built from.
+		bucket := filepath.Join("base", "bucket")
+		path := filepath.Join(bucket, filepath.FromSlash(key))
+		if !strings.HasPrefix(path, bucket+string(filepath.Separator)) {
+			t.Fatalf("key %q leaves the bucket as %q", key, path)
+		}
+		relative, err := filepath.Rel(bucket, path)
+		if err != nil || filepath.ToSlash(relative) != key {
+			t.Fatalf("key %q does not round trip: got %q, %v", key, relative, err)
+		}
+		for _, segment := range strings.Split(key, "/") {
+			if segment == hiddenDir {
+				t.Fatalf("key %q is inside the hidden directory", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+const (
+	expiresParam   = "X-Objex-Expires"
+	signatureParam = "X-Objex-Signature"
+)
+
+// PresignObject returns a URL under Config.PresignBaseURL signed with an
+// HMAC of the method, bucket, key and expiry. The URL is served by
+// PresignHandler. Objects in the root bucket cannot be presigned.
+func (s *Store) PresignObject(ctx context.Context, method, name string, expires time.Duration) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(s.presignKey) == 0 || s.presignBaseURL == "" {
+		return "", objex.ErrNotSupported
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodHead, http.MethodDelete:
+	default:
+		return "", objex.ErrNotSupported
+	}
+
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return "", err
+	}
+	if bucket == rootBucket {
+		return "", objex.ErrInvalidBucketName
+	}
+
+	presigned, err := url.Parse(s.presignBaseURL)
+	if err != nil {
+		return "", objex.ErrInvalidEndpoint
+	}
+
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+
+	presigned.Path = strings.TrimSuffix(presigned.Path, "/") + "/" + bucket + "/" + object
+	query := presigned.Query()
+	query.Set(expiresParam, expiresAt)
+	query.Set(signatureParam, hex.EncodeToString(s.sign(method, bucket, object, expiresAt)))
+	presigned.RawQuery = query.Encode()
+
+	return presigned.String(), nil
+}
+
+func (s *Store) sign(method, bucket, object, expires string) []byte {
+	mac := hmac.New(sha256.New, s.presignKey)
+	io.WriteString(mac, method+"\n"+bucket+"/"+object+"\n"+expires)
+	return mac.Sum(nil)
+}
+
+// PresignHandler serves the URLs returned by PresignObject: GET and HEAD
+// (with Range support), PUT and DELETE. Mount it at the path of
+// Config.PresignBaseURL, e.g. mux.Handle("/objex/", store.PresignHandler()).
+// PUT requests take the object's headers and x-amz-meta-* user metadata from
+// the request.
+func (s *Store) PresignHandler() http.Handler {
+	return http.HandlerFunc(s.servePresigned)
+}
+
+func (s *Store) servePresigned(w http.ResponseWriter, r *http.Request) {
+	if len(s.presignKey) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	prefix := ""
+	base, err := url.Parse(s.presignBaseURL)
+	if err == nil {
+		prefix = strings.TrimSuffix(base.Path, "/")
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, prefix+"/")
+	bucket, object, found := strings.Cut(rest, "/")
+	if !ok || !found || validBucketName(bucket) != nil || validObjectName(object) != nil || s.checkLinks(bucket, object) != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	expires := query.Get(expiresParam)
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		http.Error(w, "request has expired", http.StatusForbidden)
+		return
+	}
+
+	signature, err := hex.DecodeString(query.Get(signatureParam))
+	if err != nil || !hmac.Equal(signature, s.sign(r.Method, bucket, object, expires)) {
+		http.Error(w, "signature does not match", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		body, meta, err := s.openObject(bucket, object, objex.GetOptions{})
+		if errors.Is(err, objex.ErrObjectNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer body.Close()
+
+		file := body.(*os.File)
+		info, err := file.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", meta.ContentType)
+		setHeader(header, "Content-Disposition", meta.ContentDisposition)
+		setHeader(header, "Content-Encoding", meta.ContentEncoding)
+		setHeader(header, "Cache-Control", meta.CacheControl)
+		setHeader(header, "Content-Language", meta.ContentLanguage)
+		setHeader(header, "ETag", `"`+meta.ETag+`"`)
+		for key, value := range meta.UserMetadata {
+			header.Set("X-Amz-Meta-"+key, value)
+		}
+
+		http.ServeContent(w, r, object, info.ModTime(), file)
+
+	case http.MethodPut:
+		userMetadata := map[string]string{}
+		for key := range r.Header {
+			if strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") {
+				userMetadata[key] = r.Header.Get(key)
+			}
+		}
+
+		err := s.putObject(r.Context(), bucket, object, r.Body, objex.PutOptions{
+			ContentType:        r.Header.Get("Content-Type"),
+			ContentDisposition: r.Header.Get("Content-Disposition"),
+			ContentEncoding:    r.Header.Get("Content-Encoding"),
+			CacheControl:       r.Header.Get("Cache-Control"),
+			ContentLanguage:    r.Header.Get("Content-Language"),
+			UserMetadata:       userMetadata,
+			Conditions: objex.Conditions{
+				IfMatch:     r.Header.Get("If-Match"),
+				IfNoneMatch: r.Header.Get("If-None-Match"),
+			},
+		})
+		if errors.Is(err, objex.ErrPreconditionFailed) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodDelete:
+		err := s.deleteObject(bucket, object, objex.Conditions{IfMatch: r.Header.Get("If-Match")})
+		if errors.Is(err, objex.ErrPreconditionFailed) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func setHeader(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
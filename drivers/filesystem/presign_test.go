@@ -0,0 +1,104 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/filesystem"
+)
+
+func TestPresignHandler(t *testing.T) {
+	store, err := filesystem.NewStore(filesystem.Config{
+		BasePath:       t.TempDir(),
+		PresignKey:     "secret",
+		PresignBaseURL: "https://files.example.com/objex",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.CreateBucket("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := store.PresignHandler()
+	ctx := context.Background()
+
+	presign := func(method, name string, expires time.Duration) string {
+		t.Helper()
+
+		presigned, err := store.PresignObject(ctx, method, name, expires)
+		if err != nil {
+			t.Fatalf("PresignObject(%s, %s): %v", method, name, err)
+		}
+		return presigned
+	}
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, presign(http.MethodPut, "docs/cat.txt", time.Minute), "meow")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT: got %d %s, want 200", rec.Code, rec.Body)
+	}
+	meta, err := store.Metadata("docs/cat.txt")
+	if err != nil || meta.ContentType != "text/plain" {
+		t.Errorf("Metadata after PUT: got %+v, %v, want text/plain", meta, err)
+	}
+
+	rec = serve(http.MethodGet, presign(http.MethodGet, "docs/cat.txt", time.Minute), "")
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || string(body) != "meow" || rec.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("GET: got %d %q (%s), want 200 %q", rec.Code, body, rec.Header().Get("Content-Type"), "meow")
+	}
+
+	tampered, err := url.Parse(presign(http.MethodGet, "docs/cat.txt", time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := tampered.Query()
+	query.Set("X-Objex-Signature", strings.Repeat("0", len(query.Get("X-Objex-Signature"))))
+	tampered.RawQuery = query.Encode()
+
+	renamed := strings.Replace(presign(http.MethodGet, "docs/cat.txt", time.Minute), "/cat.txt", "/dog.txt", 1)
+
+	forbidden := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"Expired", http.MethodGet, presign(http.MethodGet, "docs/cat.txt", -time.Minute)},
+		{"TamperedSignature", http.MethodGet, tampered.String()},
+		{"OtherKey", http.MethodGet, renamed},
+		{"MethodMismatch", http.MethodDelete, presign(http.MethodGet, "docs/cat.txt", time.Minute)},
+		{"Unsigned", http.MethodGet, "https://files.example.com/objex/docs/cat.txt"},
+	}
+	for _, test := range forbidden {
+		rec := serve(test.method, test.url, "")
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: got %d %s, want 403", test.name, rec.Code, rec.Body)
+		}
+	}
+
+	// None of the rejected requests touched the object.
+	data, err := store.ReadObject("docs/cat.txt")
+	if err != nil || string(data) != "meow" {
+		t.Errorf("ReadObject: got %q, %v, want %q", data, err, "meow")
+	}
+
+	_, err = store.PresignObject(ctx, http.MethodPost, "docs/cat.txt", time.Minute)
+	if !errors.Is(err, objex.ErrNotSupported) {
+		t.Errorf("PresignObject(POST): got %v, want %v", err, objex.ErrNotSupported)
+	}
+}
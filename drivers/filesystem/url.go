@@ -0,0 +1,50 @@
+package filesystem
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/brian-nunez/objex"
+)
+
+// parseURL reads a file:// store URL:
+//
+//	file:///var/data/bucket?sha256=true
+//
+// The last path element names the current bucket and the rest is the base
+// path; end the path with a slash (file:///var/data/) to set no bucket.
+// Parameters are sha256 and crc32c, which turn on those checksums.
+func parseURL(u *url.URL) (objex.NamedConfig, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, objex.InvalidURL("file URLs cannot name a host")
+	}
+	if u.Path == "" {
+		return nil, objex.InvalidURL("missing base path")
+	}
+
+	dir, bucket := path.Split(u.Path)
+	config := Config{
+		BasePath: filepath.FromSlash(path.Clean(dir)),
+		Bucket:   bucket,
+	}
+
+	var err error
+	for name := range u.Query() {
+		value := u.Query().Get(name)
+		switch name {
+		case "sha256":
+			config.ChecksumSHA256, err = strconv.ParseBool(value)
+		case "crc32c":
+			config.ChecksumCRC32C, err = strconv.ParseBool(value)
+		default:
+			return nil, objex.InvalidURL("unknown parameter " + name)
+		}
+		if err != nil {
+			return nil, objex.InvalidURL("invalid " + name + " " + strconv.Quote(value))
+		}
+	}
+
+	return config, nil
+}
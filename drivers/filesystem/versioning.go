@@ -0,0 +1,622 @@
+package filesystem
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+// nullVersion is the version ID of objects written while versioning was off
+// or suspended.
+const nullVersion = "null"
+
+// versionRecord describes an earlier version of an object, or a delete
+// marker, kept in <bucket>/.objex/versions/<hash of key>/<id>.json. The data
+// of a version is a hard link to the file it replaced, named <id>, so
+// archiving an object never copies it.
+type versionRecord struct {
+	Key          string `json:"key"`
+	VersionID    string `json:"version_id"`
+	DeleteMarker bool   `json:"delete_marker,omitempty"`
+	// Modified is the Unix time in nanoseconds the version was written.
+	Modified int64    `json:"modified"`
+	Sidecar  *sidecar `json:"sidecar,omitempty"`
+}
+
+// newVersionID returns a version ID that sorts after the ones made before
+// it.
+func newVersionID() (string, error) {
+	suffix := make([]byte, 8)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%016x%s", time.Now().UnixNano(), hex.EncodeToString(suffix)), nil
+}
+
+// validVersionID reports whether id can name a file in the versions
+// directory.
+func validVersionID(id string) bool {
+	if id == nullVersion {
+		return true
+	}
+	if id == "" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if !('0' <= id[i] && id[i] <= '9' || 'a' <= id[i] && id[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) versioningPath(bucket string) string {
+	return filepath.Join(s.basePath, bucket, hiddenDir, "versioning")
+}
+
+func (s *Store) versionsRoot(bucket string) string {
+	return filepath.Join(s.basePath, bucket, hiddenDir, "versions")
+}
+
+// versionsDir holds the versions of one object. Keys are hashed so that
+// every object gets a single flat directory.
+func (s *Store) versionsDir(bucket, object string) string {
+	sum := sha256.Sum256([]byte(object))
+	return filepath.Join(s.versionsRoot(bucket), hex.EncodeToString(sum[:]))
+}
+
+// versioningStatus returns the status recorded for bucket, VersioningOff if
+// versioning was never set.
+func (s *Store) versioningStatus(bucket string) (objex.VersioningStatus, error) {
+	data, err := os.ReadFile(s.versioningPath(bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return objex.VersioningOff, nil
+	}
+	if err != nil {
+		return objex.VersioningOff, err
+	}
+	return objex.VersioningStatus(strings.TrimSpace(string(data))), nil
+}
+
+func (s *Store) SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	err := s.checkBucket(bucketName)
+	if err == nil {
+		err = s.requireBucket(bucketName)
+	}
+	if err != nil {
+		return toError("SetBucketVersioning", bucketName, "", err)
+	}
+
+	status := objex.VersioningSuspended
+	if enabled {
+		status = objex.VersioningEnabled
+	}
+
+	file, err := s.createAtomic(bucketName, s.versioningPath(bucketName))
+	if err != nil {
+		return toError("SetBucketVersioning", bucketName, "", err)
+	}
+	defer file.abort()
+
+	_, err = file.WriteString(string(status))
+	if err != nil {
+		return toError("SetBucketVersioning", bucketName, "", err)
+	}
+	return toError("SetBucketVersioning", bucketName, "", file.commit())
+}
+
+func (s *Store) BucketVersioning(ctx context.Context, bucketName string) (objex.VersioningStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return objex.VersioningOff, err
+	}
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	err := s.checkBucket(bucketName)
+	if err == nil {
+		err = s.requireBucket(bucketName)
+	}
+	if err != nil {
+		return objex.VersioningOff, toError("BucketVersioning", bucketName, "", err)
+	}
+
+	status, err := s.versioningStatus(bucketName)
+	return status, toError("BucketVersioning", bucketName, "", err)
+}
+
+// liveVersion returns the file info and sidecar of the current version of
+// an object, or a nil info if there is none. A sidecar that no longer
+// describes the file is replaced by an empty one: the file was changed
+// outside objex and is the null version.
+func (s *Store) liveVersion(bucket, object string) (fs.FileInfo, *sidecar, error) {
+	info, err := os.Stat(filepath.Join(s.basePath, bucket, object))
+	if errors.Is(err, os.ErrNotExist) || err == nil && info.IsDir() {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc, err := s.readSidecar(bucket, object)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sc.stale(info) {
+		sc = &sidecar{}
+	}
+	return info, sc, nil
+}
+
+func (sc *sidecar) versionID() string {
+	if sc.VersionID == "" {
+		return nullVersion
+	}
+	return sc.VersionID
+}
+
+// archiveLive keeps the current version of an object among its earlier
+// versions before it is replaced or deleted, and returns its version ID, or
+// "" if nothing was archived. With versioning suspended the null version is
+// overwritten, as in S3, so it is dropped instead.
+func (s *Store) archiveLive(bucket, object string, status objex.VersioningStatus) (string, error) {
+	if status == objex.VersioningSuspended {
+		err := s.removeVersion(bucket, object, nullVersion)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	info, sc, err := s.liveVersion(bucket, object)
+	if err != nil || info == nil {
+		return "", err
+	}
+
+	id := sc.versionID()
+	if id == nullVersion && status == objex.VersioningSuspended {
+		return "", nil
+	}
+
+	dir := s.versionsDir(bucket, object)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Link(filepath.Join(s.basePath, bucket, object), filepath.Join(dir, id))
+	if err != nil {
+		return "", err
+	}
+
+	modified := sc.Uploaded
+	if modified == 0 {
+		modified = info.ModTime().UnixNano()
+	}
+	err = s.writeVersionRecord(bucket, &versionRecord{
+		Key:       object,
+		VersionID: id,
+		Modified:  modified,
+		Sidecar:   sc,
+	})
+	if err != nil {
+		os.Remove(filepath.Join(dir, id))
+		return "", err
+	}
+	return id, nil
+}
+
+// replaceVersion prepares sc, the sidecar of a new version of an object
+// about to be committed, archiving the current version if the bucket is
+// versioned. If the commit fails, the returned function undoes the archive.
+func (s *Store) replaceVersion(bucket, object string, sc *sidecar) (func(), error) {
+	sc.VersionID = ""
+
+	status, err := s.versioningStatus(bucket)
+	if err != nil || status == objex.VersioningOff {
+		return func() {}, err
+	}
+
+	if status == objex.VersioningEnabled {
+		sc.VersionID, err = newVersionID()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	archived, err := s.archiveLive(bucket, object, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if archived != "" {
+			s.removeVersion(bucket, object, archived)
+		}
+	}, nil
+}
+
+// deleteVersioned archives the current version of an object and replaces
+// it with a delete marker.
+func (s *Store) deleteVersioned(bucket, object string, status objex.VersioningStatus) error {
+	_, err := s.archiveLive(bucket, object, status)
+	if err != nil {
+		return err
+	}
+
+	err = s.removeObject(bucket, object)
+	if err != nil {
+		return err
+	}
+
+	id := nullVersion
+	if status == objex.VersioningEnabled {
+		id, err = newVersionID()
+		if err != nil {
+			return err
+		}
+	}
+	return s.writeVersionRecord(bucket, &versionRecord{
+		Key:          object,
+		VersionID:    id,
+		DeleteMarker: true,
+		Modified:     time.Now().UnixNano(),
+	})
+}
+
+func (s *Store) writeVersionRecord(bucket string, rec *versionRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	file, err := s.createAtomic(bucket, filepath.Join(s.versionsDir(bucket, rec.Key), rec.VersionID+".json"))
+	if err != nil {
+		return err
+	}
+	defer file.abort()
+
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+	return file.commit()
+}
+
+// readVersionRecord returns the record of an earlier version of an object,
+// or nil if there is none.
+func (s *Store) readVersionRecord(bucket, object, versionID string) (*versionRecord, error) {
+	data, err := os.ReadFile(filepath.Join(s.versionsDir(bucket, object), versionID+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &versionRecord{}
+	err = json.Unmarshal(data, rec)
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// versionRecords returns the earlier versions of an object, newest first.
+func (s *Store) versionRecords(bucket, object string) ([]*versionRecord, error) {
+	return s.readRecords(s.versionsDir(bucket, object))
+}
+
+func (s *Store) readRecords(dir string) ([]*versionRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*versionRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rec := &versionRecord{}
+		err = json.Unmarshal(data, rec)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+
+	slices.SortFunc(records, func(a, b *versionRecord) int {
+		if c := cmp.Compare(b.Modified, a.Modified); c != 0 {
+			return c
+		}
+		return strings.Compare(b.VersionID, a.VersionID)
+	})
+	return records, nil
+}
+
+// removeVersion removes an earlier version of an object, and its directory
+// once it is empty.
+func (s *Store) removeVersion(bucket, object, versionID string) error {
+	dir := s.versionsDir(bucket, object)
+	err := removeIfExists(filepath.Join(dir, versionID+".json"))
+	if err != nil {
+		return err
+	}
+	err = removeIfExists(filepath.Join(dir, versionID))
+	if err != nil {
+		return err
+	}
+
+	os.Remove(dir)
+	return nil
+}
+
+// promote makes the newest earlier version of an object current again when
+// the current version was removed. Nothing is promoted if that version is
+// a delete marker, which leaves the object deleted.
+func (s *Store) promote(bucket, object string) error {
+	info, _, err := s.liveVersion(bucket, object)
+	if err != nil || info != nil {
+		return err
+	}
+
+	records, err := s.versionRecords(bucket, object)
+	if err != nil || len(records) == 0 || records[0].DeleteMarker {
+		return err
+	}
+	rec := records[0]
+
+	err = renameInto(filepath.Join(s.versionsDir(bucket, object), rec.VersionID), filepath.Join(s.basePath, bucket, object))
+	if err != nil {
+		return err
+	}
+
+	sc := rec.Sidecar
+	if sc == nil {
+		sc = &sidecar{}
+	}
+	if rec.VersionID == nullVersion {
+		sc.VersionID = ""
+	}
+	err = s.writeSidecar(bucket, object, sc)
+	if err != nil {
+		return err
+	}
+	return s.removeVersion(bucket, object, rec.VersionID)
+}
+
+func (s *Store) ListObjectVersions(ctx context.Context, bucketName, prefix string) ([]objex.ObjectVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+
+	byKey := map[string][]objex.ObjectVersion{}
+	for meta, err := range s.Objects(ctx, bucketName, objex.ListOptions{Prefix: prefix}) {
+		if err != nil {
+			return nil, err
+		}
+
+		id := meta.VersionID
+		if id == "" {
+			id = nullVersion
+		}
+		byKey[meta.Key] = append(byKey[meta.Key], objex.ObjectVersion{
+			Key:          meta.Key,
+			VersionID:    id,
+			IsLatest:     true,
+			Size:         meta.Size,
+			ETag:         meta.ETag,
+			LastModified: meta.LastModified,
+		})
+	}
+
+	root := s.versionsRoot(bucketName)
+	dirs, err := os.ReadDir(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, toError("ListObjectVersions", bucketName, "", err)
+	}
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		records, err := s.readRecords(filepath.Join(root, dir.Name()))
+		if err != nil {
+			return nil, toError("ListObjectVersions", bucketName, "", err)
+		}
+
+		for _, rec := range records {
+			if !strings.HasPrefix(rec.Key, prefix) {
+				continue
+			}
+
+			version := objex.ObjectVersion{
+				Key:            rec.Key,
+				VersionID:      rec.VersionID,
+				IsLatest:       len(byKey[rec.Key]) == 0,
+				IsDeleteMarker: rec.DeleteMarker,
+				LastModified:   time.Unix(0, rec.Modified).Format(time.RFC3339),
+			}
+			if !rec.DeleteMarker {
+				info, err := os.Stat(filepath.Join(root, dir.Name(), rec.VersionID))
+				if err != nil {
+					return nil, toError("ListObjectVersions", bucketName, "", err)
+				}
+				meta := s.versionMetaData(rec, info)
+				version.Size = meta.Size
+				version.ETag = meta.ETag
+			}
+			byKey[rec.Key] = append(byKey[rec.Key], version)
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var versions []objex.ObjectVersion
+	for _, key := range keys {
+		versions = append(versions, byKey[key]...)
+	}
+	return versions, nil
+}
+
+// versionMetaData describes the data of an earlier version.
+func (s *Store) versionMetaData(rec *versionRecord, info fs.FileInfo) *objex.ObjectMetaData {
+	meta := fileMetaData(rec.Key, info)
+	if rec.Sidecar != nil {
+		rec.Sidecar.apply(meta, info)
+	}
+	meta.VersionID = rec.VersionID
+	return meta
+}
+
+func (s *Store) OpenObjectVersion(ctx context.Context, name, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !validVersionID(versionID) {
+		return nil, nil, toError("OpenObjectVersion", bucket, object, objex.ErrVersionNotFound)
+	}
+
+	body, meta, err := s.openVersion(bucket, object, versionID, opts)
+	if err != nil {
+		return nil, nil, toError("OpenObjectVersion", bucket, object, err)
+	}
+	return body, meta, nil
+}
+
+func (s *Store) openVersion(bucket, object, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	defer s.locks.lock(bucket, object)()
+
+	info, sc, err := s.liveVersion(bucket, object)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info != nil && sc.versionID() == versionID {
+		return openFile(filepath.Join(s.basePath, bucket, object), opts, func(info fs.FileInfo) (*objex.ObjectMetaData, error) {
+			meta, err := s.objectMetaData(bucket, object, info)
+			if err != nil {
+				return nil, err
+			}
+			meta.VersionID = versionID
+			return meta, nil
+		})
+	}
+
+	rec, err := s.readVersionRecord(bucket, object, versionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rec == nil {
+		return nil, nil, objex.ErrVersionNotFound
+	}
+	if rec.DeleteMarker {
+		return nil, nil, objex.ErrObjectNotFound
+	}
+
+	return openFile(filepath.Join(s.versionsDir(bucket, object), versionID), opts, func(info fs.FileInfo) (*objex.ObjectMetaData, error) {
+		return s.versionMetaData(rec, info), nil
+	})
+}
+
+// DeleteObjectVersion succeeds for versions that do not exist, as S3 does.
+func (s *Store) DeleteObjectVersion(ctx context.Context, name, versionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return err
+	}
+	if !validVersionID(versionID) {
+		return toError("DeleteObjectVersion", bucket, object, objex.ErrVersionNotFound)
+	}
+
+	err = s.deleteVersion(bucket, object, versionID)
+	return toError("DeleteObjectVersion", bucket, object, err)
+}
+
+func (s *Store) deleteVersion(bucket, object, versionID string) error {
+	defer s.locks.lock(bucket, object)()
+
+	info, sc, err := s.liveVersion(bucket, object)
+	if err != nil {
+		return err
+	}
+	if info != nil && sc.versionID() == versionID {
+		err = s.removeObject(bucket, object)
+	} else {
+		err = s.removeVersion(bucket, object, versionID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.promote(bucket, object)
+}
+
+func (s *Store) RestoreObjectVersion(ctx context.Context, name, versionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket, object, err := s.splitPath(name)
+	if err != nil {
+		return err
+	}
+	if !validVersionID(versionID) {
+		return toError("RestoreObjectVersion", bucket, object, objex.ErrVersionNotFound)
+	}
+
+	body, meta, err := s.openVersion(bucket, object, versionID, objex.GetOptions{})
+	if err != nil {
+		return toError("RestoreObjectVersion", bucket, object, err)
+	}
+	defer body.Close()
+
+	err = s.putObject(ctx, bucket, object, body, meta.PutOptions())
+	return toError("RestoreObjectVersion", bucket, object, err)
+}
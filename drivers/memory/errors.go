@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"errors"
+
+	"github.com/brian-nunez/objex"
+)
+
+// toError describes a failed call as an *objex.Error whose Kind is the Err*
+// sentinel err matches. Errors that already are *objex.Error are returned
+// unchanged.
+func toError(op, bucket, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *objex.Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return &objex.Error{
+		Kind:   objex.KindOf(err),
+		Op:     op,
+		Bucket: bucket,
+		Key:    key,
+		Err:    err,
+	}
+}
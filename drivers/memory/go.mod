@@ -0,0 +1,7 @@
+module github.com/brian-nunez/objex/drivers/memory
+
+go 1.23.0
+
+require github.com/brian-nunez/objex v1.0.3
+
+replace github.com/brian-nunez/objex => ../../
@@ -0,0 +1,624 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+const driverName = "memory"
+
+func init() {
+	objex.Register(driverName, func(config any) (objex.Store, error) {
+		conf, ok := config.(Config)
+		if !ok {
+			return nil, objex.ErrClientInit
+		}
+		return NewStore(conf)
+	})
+	objex.RegisterURL("mem", parseURL)
+	objex.RegisterConfig[Config]()
+}
+
+// Config configures an in-memory store. Every store starts empty and its
+// contents are lost with it.
+type Config struct {
+	// Bucket, when set, is created and selected as the current bucket.
+	Bucket string `json:"bucket"`
+}
+
+func (c Config) DriverName() string {
+	return driverName
+}
+
+// Store keeps buckets and objects in memory. It is safe for concurrent use
+// and is meant for unit tests that should not touch disk or the network.
+type Store struct {
+	*state
+	// bucket is the current bucket, guarded by mu.
+	bucket string
+}
+
+// state is shared by a Store and the handles returned by Bucket.
+type state struct {
+	mu      sync.RWMutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	created time.Time
+	objects map[string]*object
+}
+
+// object is never changed once stored; writes replace it.
+type object struct {
+	data []byte
+	meta objex.ObjectMetaData
+}
+
+func NewStore(config Config) (*Store, error) {
+	s := &Store{state: &state{buckets: make(map[string]*bucket)}}
+	if config.Bucket != "" {
+		s.buckets[config.Bucket] = newBucket()
+		s.bucket = config.Bucket
+	}
+	return s, nil
+}
+
+func newBucket() *bucket {
+	return &bucket{created: time.Now().UTC(), objects: make(map[string]*object)}
+}
+
+func (s *Store) Setup() error {
+	return s.SetupContext(context.Background())
+}
+
+func (s *Store) SetupContext(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) SetBucket(bucketName string) (bool, error) {
+	return s.SetBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) SetBucketContext(ctx context.Context, bucketName string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if bucketName == "" {
+		s.bucket = ""
+		return false, nil
+	}
+	if _, ok := s.buckets[bucketName]; !ok {
+		return false, toError("SetBucket", bucketName, "", objex.ErrBucketNotFound)
+	}
+	s.bucket = bucketName
+	return true, nil
+}
+
+// CurrentBucket returns the bucket set by Config.Bucket or SetBucket.
+func (s *Store) CurrentBucket() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bucket
+}
+
+// Bucket returns a handle bound to bucketName that shares the store's
+// buckets and objects.
+func (s *Store) Bucket(bucketName string) objex.BucketHandle {
+	return objex.NewBucketHandle(bucketName, &Store{state: s.state, bucket: bucketName})
+}
+
+func (s *Store) SetRegion(region string) error {
+	return s.SetRegionContext(context.Background(), region)
+}
+
+func (s *Store) SetRegionContext(ctx context.Context, region string) error {
+	// Not applicable for memory
+	return nil
+}
+
+func (s *Store) CreateBucket(bucketName string) error {
+	return s.CreateBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) CreateBucketContext(ctx context.Context, bucketName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if bucketName == "" || strings.Contains(bucketName, "/") {
+		return toError("CreateBucket", bucketName, "", objex.ErrInvalidBucketName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.buckets[bucketName]; ok {
+		return toError("CreateBucket", bucketName, "", objex.ErrBucketAlreadyExists)
+	}
+	s.buckets[bucketName] = newBucket()
+	return nil
+}
+
+func (s *Store) DeleteBucket(bucketName string) error {
+	return s.DeleteBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) DeleteBucketContext(ctx context.Context, bucketName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if bucketName == "" {
+		return toError("DeleteBucket", bucketName, "", objex.ErrInvalidBucketName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	if len(b.objects) > 0 {
+		return toError("DeleteBucket", bucketName, "", objex.ErrBucketNotEmpty)
+	}
+	delete(s.buckets, bucketName)
+	return nil
+}
+
+func (s *Store) ListBuckets() ([]objex.Bucket, error) {
+	return s.ListBucketsContext(context.Background())
+}
+
+func (s *Store) ListBucketsContext(ctx context.Context) ([]objex.Bucket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var buckets []objex.Bucket
+	for _, name := range slices.Sorted(maps.Keys(s.buckets)) {
+		buckets = append(buckets, objex.Bucket{
+			Name:         name,
+			CreationDate: s.buckets[name].created.Format(time.RFC3339),
+		})
+	}
+	return buckets, nil
+}
+
+// splitPath resolves name against the current bucket.
+func (s *Store) splitPath(name string) (string, string, error) {
+	s.mu.RLock()
+	current := s.bucket
+	s.mu.RUnlock()
+
+	bucketName, key, err := objex.SplitPath(current, name)
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", objex.ErrInvalidObjectName
+	}
+	return bucketName, key, nil
+}
+
+// lookup returns the bucket and the object stored under key, which is nil
+// when there is none. The caller must hold s.mu.
+func (s *Store) lookup(bucketName, key string) (*bucket, *object, error) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, nil, objex.ErrBucketNotFound
+	}
+	return b, b.objects[key], nil
+}
+
+func (s *Store) CreateObject(name string, data io.Reader, contentType string) error {
+	return s.CreateObjectContext(context.Background(), name, data, contentType)
+}
+
+func (s *Store) CreateObjectContext(ctx context.Context, name string, data io.Reader, contentType string) error {
+	return s.PutObject(ctx, name, data, objex.PutOptions{ContentType: contentType})
+}
+
+func (s *Store) PutObject(ctx context.Context, name string, data io.Reader, opts objex.PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucketName, key, err := s.splitPath(name)
+	if err != nil {
+		return toError("PutObject", "", name, err)
+	}
+
+	err = s.putObject(ctx, bucketName, key, data, opts)
+	return toError("PutObject", bucketName, key, err)
+}
+
+func (s *Store) putObject(ctx context.Context, bucketName, key string, data io.Reader, opts objex.PutOptions) error {
+	body, err := io.ReadAll(objex.ContextReader(ctx, data))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, current, err := s.lookup(bucketName, key)
+	if err != nil {
+		return err
+	}
+	err = opts.Conditions.Check(current.metaData())
+	if err != nil {
+		return err
+	}
+
+	b.objects[key] = newObject(key, body, opts)
+	return nil
+}
+
+func newObject(key string, data []byte, opts objex.PutOptions) *object {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	sum := md5.Sum(data)
+	return &object{
+		data: data,
+		meta: objex.ObjectMetaData{
+			Key:                key,
+			Size:               int64(len(data)),
+			ContentType:        contentType,
+			ETag:               hex.EncodeToString(sum[:]),
+			LastModified:       time.Now().UTC().Format(time.RFC3339),
+			ContentDisposition: opts.ContentDisposition,
+			ContentEncoding:    opts.ContentEncoding,
+			CacheControl:       opts.CacheControl,
+			ContentLanguage:    opts.ContentLanguage,
+			UserMetadata:       objex.NormalizeUserMetadata(opts.UserMetadata),
+		},
+	}
+}
+
+// metaData returns a copy of the object's metadata, or nil for a missing
+// object.
+func (o *object) metaData() *objex.ObjectMetaData {
+	if o == nil {
+		return nil
+	}
+	meta := o.meta
+	meta.UserMetadata = maps.Clone(o.meta.UserMetadata)
+	return &meta
+}
+
+func (s *Store) ReadObject(name string) ([]byte, error) {
+	return s.ReadObjectContext(context.Background(), name)
+}
+
+func (s *Store) ReadObjectContext(ctx context.Context, name string) ([]byte, error) {
+	return objex.ReadObject(ctx, s, name)
+}
+
+func (s *Store) OpenObject(ctx context.Context, name string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	bucketName, key, err := s.splitPath(name)
+	if err != nil {
+		return nil, nil, toError("OpenObject", "", name, err)
+	}
+
+	body, meta, err := s.openObject(bucketName, key, opts)
+	if err != nil {
+		return nil, nil, toError("OpenObject", bucketName, key, err)
+	}
+	return body, meta, nil
+}
+
+func (s *Store) openObject(bucketName, key string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	s.mu.RLock()
+	_, obj, err := s.lookup(bucketName, key)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if obj == nil {
+		return nil, nil, objex.ErrObjectNotFound
+	}
+
+	meta := obj.metaData()
+	err = opts.Conditions.Check(meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := obj.data
+	if opts.Range != nil {
+		offset, length, err := opts.Range.Bounds(int64(len(data)))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = data[offset : offset+length]
+		meta.Size = length
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), meta, nil
+}
+
+func (s *Store) UpdateObject(name string, data io.Reader) error {
+	return s.UpdateObjectContext(context.Background(), name, data)
+}
+
+func (s *Store) UpdateObjectContext(ctx context.Context, name string, data io.Reader) error {
+	exists, meta, err := s.ExistsContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		bucketName, key, _ := s.splitPath(name)
+		return toError("UpdateObject", bucketName, key, objex.ErrObjectNotFound)
+	}
+
+	return s.PutObject(ctx, name, data, meta.PutOptions())
+}
+
+func (s *Store) DeleteObject(name string) error {
+	return s.DeleteObjectContext(context.Background(), name)
+}
+
+func (s *Store) DeleteObjectContext(ctx context.Context, name string) error {
+	return s.DeleteObjectIf(ctx, name, objex.Conditions{})
+}
+
+func (s *Store) DeleteObjectIf(ctx context.Context, name string, cond objex.Conditions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucketName, key, err := s.splitPath(name)
+	if err != nil {
+		return toError("DeleteObject", "", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, obj, err := s.lookup(bucketName, key)
+	if err == nil {
+		err = cond.Check(obj.metaData())
+	}
+	if err != nil {
+		return toError("DeleteObject", bucketName, key, err)
+	}
+
+	// Like S3, deleting a missing object succeeds.
+	delete(b.objects, key)
+	return nil
+}
+
+func (s *Store) ListObjects(bucketName string) ([]*objex.ObjectMetaData, error) {
+	return s.ListObjectsContext(context.Background(), bucketName)
+}
+
+func (s *Store) ListObjectsContext(ctx context.Context, bucketName string) ([]*objex.ObjectMetaData, error) {
+	return s.listObjects(ctx, bucketName, "")
+}
+
+func (s *Store) ListObjectsPage(ctx context.Context, bucketName string, opts objex.ListOptions) (*objex.ListResult, error) {
+	objects, err := s.listObjects(ctx, bucketName, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	return objex.PageObjects(objects, opts), nil
+}
+
+func (s *Store) Objects(ctx context.Context, bucketName string, opts objex.ListOptions) iter.Seq2[*objex.ObjectMetaData, error] {
+	return func(yield func(*objex.ObjectMetaData, error) bool) {
+		// Work on a snapshot so the loop body may write to the store.
+		objects, err := s.listObjects(ctx, bucketName, opts.Prefix)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		after := max(opts.StartAfter, opts.ContinuationToken)
+		for _, meta := range objects {
+			if meta.Key <= after {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(meta, nil) {
+				return
+			}
+		}
+	}
+}
+
+// listObjects returns the objects of bucketName, or of the current bucket,
+// whose keys start with prefix, in key order.
+func (s *Store) listObjects(ctx context.Context, bucketName, prefix string) ([]*objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, toError("ListObjects", bucketName, "", objex.ErrBucketNotFound)
+	}
+
+	var objects []*objex.ObjectMetaData
+	for _, key := range slices.Sorted(maps.Keys(b.objects)) {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		// Listings do not carry user metadata, as on S3.
+		meta := b.objects[key].meta
+		meta.UserMetadata = nil
+		objects = append(objects, &meta)
+	}
+	return objects, nil
+}
+
+func (s *Store) Exists(name string) (bool, *objex.ObjectMetaData, error) {
+	return s.ExistsContext(context.Background(), name)
+}
+
+func (s *Store) ExistsContext(ctx context.Context, name string) (bool, *objex.ObjectMetaData, error) {
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+	bucketName, key, err := s.splitPath(name)
+	if err != nil {
+		return false, nil, toError("Exists", "", name, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, obj, err := s.lookup(bucketName, key)
+	if err != nil {
+		return false, nil, toError("Exists", bucketName, key, err)
+	}
+	return obj != nil, obj.metaData(), nil
+}
+
+func (s *Store) Metadata(name string) (*objex.ObjectMetaData, error) {
+	return s.MetadataContext(context.Background(), name)
+}
+
+func (s *Store) MetadataContext(ctx context.Context, name string) (*objex.ObjectMetaData, error) {
+	found, meta, err := s.ExistsContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		bucketName, key, _ := s.splitPath(name)
+		return nil, toError("Metadata", bucketName, key, objex.ErrObjectNotFound)
+	}
+	return meta, nil
+}
+
+func (s *Store) CopyObject(src, dest string) error {
+	return s.CopyObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) CopyObjectContext(ctx context.Context, src, dest string) error {
+	return s.CopyObjectIf(ctx, src, dest, objex.CopyConditions{})
+}
+
+func (s *Store) CopyObjectIf(ctx context.Context, src, dest string, cond objex.CopyConditions) error {
+	return s.copyObject(ctx, src, dest, cond, false)
+}
+
+func (s *Store) MoveObject(src, dest string) error {
+	return s.MoveObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) MoveObjectContext(ctx context.Context, src, dest string) error {
+	return s.copyObject(ctx, src, dest, objex.CopyConditions{}, true)
+}
+
+// copyObject copies src to dest, and removes src when move is set, as one
+// step so no other call sees the object in both places or in neither.
+func (s *Store) copyObject(ctx context.Context, src, dest string, cond objex.CopyConditions, move bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	op := "CopyObject"
+	if move {
+		op = "MoveObject"
+	}
+
+	srcBucket, srcKey, err := s.splitPath(src)
+	if err != nil {
+		return toError(op, "", src, err)
+	}
+	destBucket, destKey, err := s.splitPath(dest)
+	if err != nil {
+		return toError(op, "", dest, err)
+	}
+
+	err = s.copyKey(srcBucket, srcKey, destBucket, destKey, cond, move)
+	return toError(op, srcBucket, srcKey, err)
+}
+
+func (s *Store) copyKey(srcBucket, srcKey, destBucket, destKey string, cond objex.CopyConditions, move bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srcB, obj, err := s.lookup(srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	if obj == nil {
+		return objex.ErrObjectNotFound
+	}
+	err = cond.Source.Check(obj.metaData())
+	if err != nil {
+		return err
+	}
+
+	destB, current, err := s.lookup(destBucket, destKey)
+	if err != nil {
+		return err
+	}
+	err = cond.Destination.Check(current.metaData())
+	if err != nil {
+		return err
+	}
+
+	destB.objects[destKey] = newObject(destKey, obj.data, obj.metaData().PutOptions())
+	if move && (srcBucket != destBucket || srcKey != destKey) {
+		delete(srcB.objects, srcKey)
+	}
+	return nil
+}
+
+func (s *Store) CleanUp() error {
+	return s.CleanUpContext(context.Background())
+}
+
+// CleanUpContext does nothing, as in the other drivers: buckets and objects
+// stay until the store is dropped.
+func (s *Store) CleanUpContext(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) Capabilities() objex.Capabilities {
+	return objex.Capabilities{
+		ServerSideCopy: true,
+		AllConditions:  true,
+	}
+}
+
+func (s *Store) HealthCheck() error {
+	return s.HealthCheckContext(context.Background())
+}
+
+func (s *Store) HealthCheckContext(ctx context.Context) error {
+	return ctx.Err()
+}
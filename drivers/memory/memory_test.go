@@ -0,0 +1,113 @@
+package memory_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+	"github.com/brian-nunez/objex/objextest"
+)
+
+func TestConformance(t *testing.T) {
+	objextest.Run(t, func(t *testing.T) objex.Store {
+		store, err := memory.NewStore(memory.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    memory.Config
+		wantErr error
+	}{
+		{url: "mem://", want: memory.Config{}},
+		{url: "mem://photos", want: memory.Config{Bucket: "photos"}},
+		{url: "mem:///photos", want: memory.Config{Bucket: "photos"}},
+		{url: "mem://photos/thumbnails", wantErr: objex.ErrInvalidEndpoint},
+		{url: "mem:///photos/thumbnails", wantErr: objex.ErrInvalidEndpoint},
+		{url: "mem://photos?size=10", wantErr: objex.ErrInvalidEndpoint},
+	}
+
+	for _, test := range tests {
+		config, err := objex.ParseURL(test.url)
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("ParseURL(%s): got error %v, want %v", test.url, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil || config != test.want {
+			t.Errorf("ParseURL(%s): got %+v, %v, want %+v", test.url, config, err, test.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	store, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		call func() error
+		want objex.Error
+	}{
+		{func() error {
+			_, err := store.ReadObject("cat.jpg")
+			return err
+		}, objex.Error{Kind: objex.ErrObjectNotFound, Op: "OpenObject", Bucket: "photos", Key: "cat.jpg"}},
+		{func() error {
+			_, err := store.Metadata("cat.jpg")
+			return err
+		}, objex.Error{Kind: objex.ErrObjectNotFound, Op: "Metadata", Bucket: "photos", Key: "cat.jpg"}},
+		{func() error {
+			return store.MoveObject("cat.jpg", "dog.jpg")
+		}, objex.Error{Kind: objex.ErrObjectNotFound, Op: "MoveObject", Bucket: "photos", Key: "cat.jpg"}},
+		{func() error {
+			_, err := store.ListObjects("videos")
+			return err
+		}, objex.Error{Kind: objex.ErrBucketNotFound, Op: "ListObjects", Bucket: "videos"}},
+		{func() error {
+			return store.CreateBucket("photos")
+		}, objex.Error{Kind: objex.ErrBucketAlreadyExists, Op: "CreateBucket", Bucket: "photos"}},
+	}
+
+	for _, test := range tests {
+		err := test.call()
+
+		var e *objex.Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: got %#v, want an *objex.Error", test.want.Op, err)
+			continue
+		}
+		if e.Kind != test.want.Kind || e.Op != test.want.Op || e.Bucket != test.want.Bucket || e.Key != test.want.Key {
+			t.Errorf("%s: got %v, want %v", test.want.Op, e, &test.want)
+		}
+	}
+}
+
+func TestCleanUpKeepsObjects(t *testing.T) {
+	store, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.CreateObject("cat.jpg", strings.NewReader("meow"), "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.CleanUp()
+	if err != nil {
+		t.Fatalf("CleanUp: %v", err)
+	}
+	data, err := store.ReadObject("cat.jpg")
+	if err != nil || string(data) != "meow" {
+		t.Errorf("ReadObject after CleanUp: got %q, %v, want %q", data, err, "meow")
+	}
+}
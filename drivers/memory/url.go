@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/brian-nunez/objex"
+)
+
+// parseURL reads a mem:// store URL. "mem://" is an empty store and
+// "mem://bucket" one with bucket created and selected.
+func parseURL(u *url.URL) (objex.NamedConfig, error) {
+	bucket := u.Host
+	if name := strings.Trim(u.Path, "/"); name != "" {
+		if bucket != "" || strings.Contains(name, "/") {
+			return nil, objex.InvalidURL("mem URLs name at most one bucket")
+		}
+		bucket = name
+	}
+	if len(u.Query()) > 0 {
+		return nil, objex.InvalidURL("mem URLs take no parameters")
+	}
+	return Config{Bucket: bucket}, nil
+}
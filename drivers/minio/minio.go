@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"io"
-	"log"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/brian-nunez/objex"
 	"github.com/minio/minio-go/v7"
@@ -23,69 +27,131 @@ func init() {
 
 		return NewStore(typed)
 	})
+	objex.RegisterURL("minio", parseURL)
+	objex.RegisterConfig[Config]()
 }
 
 type Config struct {
-	Endpoint     string
-	AccessKey    string
-	SecretKey    string
-	Token        string
-	UseSSL       bool
-	Region       string
-	UsePathStyle bool
+	Endpoint     string `json:"endpoint"`
+	AccessKey    string `json:"access_key"`
+	SecretKey    string `json:"secret_key"`
+	Token        string `json:"token"`
+	UseSSL       bool   `json:"use_ssl"`
+	Region       string `json:"region"`
+	UsePathStyle bool   `json:"use_path_style"`
+	// Bucket, when set, is used as the current bucket. Unlike SetBucket,
+	// NewStore does not check that it exists.
+	Bucket string `json:"bucket"`
+	// Logger receives the driver's diagnostics. Nil discards them.
+	Logger *slog.Logger `json:"-"`
 }
 
 func (c Config) DriverName() string {
 	return driverName
 }
 
+// Validate reports the first required field that is missing: Endpoint,
+// AccessKey or SecretKey.
+func (c Config) Validate() error {
+	if c.Endpoint == "" {
+		return objex.ErrInvalidEndpoint
+	}
+
+	if c.AccessKey == "" {
+		return objex.ErrInvalidAccessKey
+	}
+
+	if c.SecretKey == "" {
+		return objex.ErrInvalidSecretKey
+	}
+
+	return nil
+}
+
 type Store struct {
 	config Config
 	client *minio.Client
 	bucket string
+	logger *slog.Logger
 }
 
+// ToStandardError describes a minio error as an *objex.Error, with the kind
+// taken from the S3 error code. Errors that are not S3 responses, such as
+// network failures or a canceled context, are kept as the cause without a
+// kind.
 func ToStandardError(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	code := minio.ToErrorResponse(err).Code
-
-	if code == "" {
-		return nil
+	var e *objex.Error
+	if errors.As(err, &e) {
+		return err
 	}
 
-	if code == "NoSuchBucket" {
-		return objex.ErrBucketNotFound
-	}
+	e = &objex.Error{Err: err}
 
-	if code == "NoSuchKey" {
-		return objex.ErrObjectNotFound
+	response := minio.ToErrorResponse(err)
+	if response.Code == "" && response.StatusCode == 0 {
+		return e
 	}
 
-	if code == "AccessDenied" {
-		return objex.ErrAccessDenied
-	}
+	e.Bucket = response.BucketName
+	e.Key = response.Key
+	e.Code = response.Code
+	e.StatusCode = response.StatusCode
+	e.Kind = objex.S3ErrorKind(response.Code, response.StatusCode)
 
-	if code == "Conflict" {
-		return objex.ErrBucketNotEmpty
+	// minio answers deleting a bucket that still has objects with a bare
+	// 409 Conflict.
+	if response.Code == "Conflict" {
+		e.Kind = objex.ErrBucketNotEmpty
 	}
 
-	if code == "PreconditionFailed" {
-		return objex.ErrPreconditionFailed
-	}
+	return e
+}
+
+// toError is ToStandardError with the failed operation and the bucket and
+// key it was called with.
+func toError(op, bucket, key string, err error) error {
+	standardErr := ToStandardError(err)
 
-	if code == "BucketAlreadyOwnedByYou" {
-		return objex.ErrBucketAlreadyExists
+	var e *objex.Error
+	if errors.As(standardErr, &e) && e.Op == "" {
+		e.Op = op
+		e.Bucket = bucket
+		e.Key = key
 	}
 
-	return errors.New(code)
+	return standardErr
+}
+
+func toMetaData(objectItem minio.ObjectInfo) *objex.ObjectMetaData {
+	return &objex.ObjectMetaData{
+		Key:                objectItem.Key,
+		LastModified:       objectItem.LastModified.String(),
+		ETag:               objectItem.ETag,
+		Size:               objectItem.Size,
+		ContentType:        objectItem.ContentType,
+		ContentDisposition: objectItem.Metadata.Get("Content-Disposition"),
+		ContentEncoding:    objectItem.Metadata.Get("Content-Encoding"),
+		CacheControl:       objectItem.Metadata.Get("Cache-Control"),
+		ContentLanguage:    objectItem.Metadata.Get("Content-Language"),
+		UserMetadata:       objex.NormalizeUserMetadata(objectItem.UserMetadata),
+		VersionID:          objectItem.VersionID,
+	}
 }
 
 func NewStore(config Config) (*Store, error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = objex.DiscardLogger()
+	}
+
 	store := &Store{
 		config: config,
+		bucket: config.Bucket,
+		logger: logger.With("driver", driverName),
 	}
 
 	err := store.HealthCheck()
@@ -93,6 +159,15 @@ func NewStore(config Config) (*Store, error) {
 		return nil, err
 	}
 
+	if store.config.Region == "" {
+		store.logger.Warn("region is not set, defaulting to us-east-1")
+		store.config.Region = "us-east-1"
+	}
+
+	if !config.UseSSL {
+		store.logger.Warn("using HTTP instead of HTTPS", "endpoint", config.Endpoint)
+	}
+
 	minioClient, err := minio.New(config.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, config.Token),
 		Secure: config.UseSSL,
@@ -107,46 +182,47 @@ func NewStore(config Config) (*Store, error) {
 }
 
 func (s *Store) Setup() error {
-	return nil
+	return s.SetupContext(context.Background())
 }
 
-func (s *Store) HealthCheck() error {
-	if s.config.Endpoint == "" {
-		return objex.ErrInvalidEndpoint
-	}
-
-	if s.config.AccessKey == "" {
-		return objex.ErrInvalidAccessKey
-	}
-
-	if s.config.SecretKey == "" {
-		return objex.ErrInvalidSecretKey
-	}
+func (s *Store) SetupContext(ctx context.Context) error {
+	return nil
+}
 
-	if s.config.Region == "" {
-		log.Println("[Objex Minio] Warning: Region is not set, defaulting to 'us-east-1'")
-		s.config.Region = "us-east-1"
+// Capabilities reports presigning, multipart uploads, server-side copies
+// and versioning, all of which MinIO provides. MinIO object lock is not
+// reported, since objex has no API to use it.
+func (s *Store) Capabilities() objex.Capabilities {
+	return objex.Capabilities{
+		Presign:        true,
+		Multipart:      true,
+		ServerSideCopy: true,
+		Versioning:     true,
 	}
+}
 
-	if !s.config.UseSSL {
-		log.Println("[Objex Minio] Warning: Using HTTP instead of HTTPS")
-	}
+func (s *Store) HealthCheck() error {
+	return s.HealthCheckContext(context.Background())
+}
 
-	return nil
+func (s *Store) HealthCheckContext(ctx context.Context) error {
+	return s.config.Validate()
 }
 
 func (s *Store) SetBucket(bucketName string) (found bool, err error) {
+	return s.SetBucketContext(context.Background(), bucketName)
+}
+
+func (s *Store) SetBucketContext(ctx context.Context, bucketName string) (found bool, err error) {
 	if bucketName == "" {
-		log.Println("[Objex Minio] Warning: Empty bucket name, using full path for objects")
+		s.logger.DebugContext(ctx, "no bucket set, object names include the bucket")
 		s.bucket = ""
 		return false, nil
 	}
 
-	found, err = s.client.BucketExists(context.Background(), bucketName)
+	found, err = s.client.BucketExists(ctx, bucketName)
 	if err != nil {
-		standardErr := minio.ToErrorResponse(err)
-
-		return found, standardErr
+		return found, toError("SetBucket", bucketName, "", err)
 	}
 
 	if !found {
@@ -158,9 +234,28 @@ func (s *Store) SetBucket(bucketName string) (found bool, err error) {
 	return found, nil
 }
 
+// CurrentBucket returns the bucket set by Config.Bucket or SetBucket.
+func (s *Store) CurrentBucket() string {
+	return s.bucket
+}
+
+// Bucket returns a handle bound to bucketName that shares the store's client.
+func (s *Store) Bucket(bucketName string) objex.BucketHandle {
+	return objex.NewBucketHandle(bucketName, &Store{
+		config: s.config,
+		client: s.client,
+		bucket: bucketName,
+		logger: s.logger,
+	})
+}
+
 func (s *Store) SetRegion(region string) error {
+	return s.SetRegionContext(context.Background(), region)
+}
+
+func (s *Store) SetRegionContext(ctx context.Context, region string) error {
 	if region == "" {
-		log.Println("[Objex Minio] Warning: Region is not set, defaulting to 'us-east-1'")
+		s.logger.WarnContext(ctx, "region is not set, defaulting to us-east-1")
 		region = "us-east-1"
 	}
 	s.config.Region = region
@@ -168,19 +263,23 @@ func (s *Store) SetRegion(region string) error {
 }
 
 func (s *Store) CreateBucket(name string) error {
+	return s.CreateBucketContext(context.Background(), name)
+}
+
+func (s *Store) CreateBucketContext(ctx context.Context, name string) error {
 	if name == "" {
 		return objex.ErrInvalidBucketName
 	}
 
 	err := s.client.MakeBucket(
-		context.Background(),
+		ctx,
 		name,
 		minio.MakeBucketOptions{
 			Region: s.config.Region,
 		},
 	)
 
-	standardErr := ToStandardError(err)
+	standardErr := toError("CreateBucket", name, "", err)
 	if standardErr != nil {
 		return standardErr
 	}
@@ -189,14 +288,18 @@ func (s *Store) CreateBucket(name string) error {
 }
 
 func (s *Store) DeleteBucket(name string) error {
+	return s.DeleteBucketContext(context.Background(), name)
+}
+
+func (s *Store) DeleteBucketContext(ctx context.Context, name string) error {
 	if name == "" {
 		return objex.ErrInvalidBucketName
 	}
 
-	err := s.client.RemoveBucket(context.Background(), name)
+	err := s.client.RemoveBucket(ctx, name)
 	if err != nil {
-		standardErr := ToStandardError(err)
-		if standardErr == objex.ErrBucketNotFound {
+		standardErr := toError("DeleteBucket", name, "", err)
+		if errors.Is(standardErr, objex.ErrBucketNotFound) {
 			return nil
 		}
 
@@ -207,9 +310,13 @@ func (s *Store) DeleteBucket(name string) error {
 }
 
 func (s *Store) ListBuckets() ([]objex.Bucket, error) {
-	buckets, err := s.client.ListBuckets(context.Background())
+	return s.ListBucketsContext(context.Background())
+}
+
+func (s *Store) ListBucketsContext(ctx context.Context) ([]objex.Bucket, error) {
+	buckets, err := s.client.ListBuckets(ctx)
 	if err != nil {
-		return nil, ToStandardError(err)
+		return nil, toError("ListBuckets", "", "", err)
 	}
 
 	var bucketItems []objex.Bucket
@@ -224,6 +331,14 @@ func (s *Store) ListBuckets() ([]objex.Bucket, error) {
 }
 
 func (s *Store) CreateObject(name string, data io.Reader, contentType string) error {
+	return s.CreateObjectContext(context.Background(), name, data, contentType)
+}
+
+func (s *Store) CreateObjectContext(ctx context.Context, name string, data io.Reader, contentType string) error {
+	return s.PutObject(ctx, name, data, objex.PutOptions{ContentType: contentType})
+}
+
+func (s *Store) PutObject(ctx context.Context, name string, data io.Reader, opts objex.PutOptions) error {
 	if name == "" {
 		return objex.ErrInvalidObjectName
 	}
@@ -233,27 +348,32 @@ func (s *Store) CreateObject(name string, data io.Reader, contentType string) er
 		return err
 	}
 
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	// S3 only supports ETag conditions on writes.
+	if opts.Conditions.HasDates() {
+		return objex.ErrNotSupported
 	}
 
-	_, size, err := objex.GetStreamSize(data)
-	if err != nil {
-		return objex.ErrPreconditionFailed
+	// Seekable readers report their size. Anything else is streamed with
+	// an unknown size, which minio-go uploads in parts instead of holding
+	// the whole object in memory.
+	size := int64(-1)
+	if _, ok := data.(io.Seeker); ok {
+		data, size, err = objex.GetStreamSize(data)
+		if err != nil {
+			return toError("PutObject", bucketName, fileName, err)
+		}
 	}
 
 	_, err = s.client.PutObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		fileName,
 		data,
 		size,
-		minio.PutObjectOptions{
-			ContentType: contentType,
-		},
+		putObjectOptions(opts),
 	)
 
-	standardErr := ToStandardError(err)
+	standardErr := toError("PutObject", bucketName, fileName, err)
 	if standardErr != nil {
 		return standardErr
 	}
@@ -261,42 +381,138 @@ func (s *Store) CreateObject(name string, data io.Reader, contentType string) er
 	return nil
 }
 
+func putObjectOptions(opts objex.PutOptions) minio.PutObjectOptions {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	putOpts := minio.PutObjectOptions{
+		ContentType:        contentType,
+		ContentDisposition: opts.ContentDisposition,
+		ContentEncoding:    opts.ContentEncoding,
+		CacheControl:       opts.CacheControl,
+		ContentLanguage:    opts.ContentLanguage,
+		UserMetadata:       objex.NormalizeUserMetadata(opts.UserMetadata),
+	}
+	if opts.Conditions.IfMatch != "" {
+		putOpts.SetMatchETag(opts.Conditions.IfMatch)
+	}
+	if opts.Conditions.IfNoneMatch != "" {
+		putOpts.SetMatchETagExcept(opts.Conditions.IfNoneMatch)
+	}
+	return putOpts
+}
+
 func (s *Store) ReadObject(name string) ([]byte, error) {
+	return s.ReadObjectContext(context.Background(), name)
+}
+
+func (s *Store) ReadObjectContext(ctx context.Context, name string) ([]byte, error) {
+	return objex.ReadObject(ctx, s, name)
+}
+
+func (s *Store) OpenObject(ctx context.Context, name string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
 	if name == "" {
-		return nil, objex.ErrInvalidObjectName
+		return nil, nil, objex.ErrInvalidObjectName
 	}
 
 	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	body, meta, err := s.openObject(ctx, bucketName, fileName, "", opts)
+	if err != nil {
+		return nil, nil, toError("OpenObject", bucketName, fileName, err)
+	}
+	return body, meta, nil
+}
+
+// openObject opens versionID of the object, or its latest version when
+// versionID is empty.
+func (s *Store) openObject(ctx context.Context, bucketName, fileName, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	getOpts := minio.GetObjectOptions{VersionID: versionID}
+	if opts.Range != nil {
+		err := setRange(&getOpts, *opts.Range)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	err := setConditions(&getOpts, opts.Conditions)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	object, err := s.client.GetObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		fileName,
-		minio.GetObjectOptions{},
+		getOpts,
 	)
 	if err != nil {
-		standardErr := ToStandardError(err)
-		if standardErr == objex.ErrObjectNotFound {
-			return nil, nil
+		return nil, nil, err
+	}
+
+	// GetObject is lazy; Stat issues the request so a missing key is
+	// reported here rather than on the first Read.
+	objectItem, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, err
+	}
+
+	return object, toMetaData(objectItem), nil
+}
+
+func setConditions(opts *minio.GetObjectOptions, cond objex.Conditions) error {
+	if cond.IfMatch != "" {
+		if err := opts.SetMatchETag(cond.IfMatch); err != nil {
+			return err
+		}
+	}
+	if cond.IfNoneMatch != "" {
+		if err := opts.SetMatchETagExcept(cond.IfNoneMatch); err != nil {
+			return err
+		}
+	}
+	if !cond.IfModifiedSince.IsZero() {
+		if err := opts.SetModified(cond.IfModifiedSince); err != nil {
+			return err
 		}
+	}
+	if !cond.IfUnmodifiedSince.IsZero() {
+		if err := opts.SetUnmodified(cond.IfUnmodifiedSince); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		return nil, standardErr
+func setRange(opts *minio.GetObjectOptions, rng objex.Range) error {
+	if err := rng.Validate(); err != nil {
+		return err
 	}
-	defer object.Close()
 
-	objectData, err := io.ReadAll(object)
-	if err != nil {
-		return nil, err
+	switch {
+	case rng.Suffix > 0:
+		return opts.SetRange(0, -rng.Suffix)
+	case rng.Length > 0:
+		return opts.SetRange(rng.Offset, rng.Offset+rng.Length-1)
+	case rng.Offset > 0:
+		return opts.SetRange(rng.Offset, 0)
 	}
 
-	return objectData, nil
+	// Offset 0 with no length is the whole object.
+	return nil
 }
 
 func (s *Store) UpdateObject(name string, data io.Reader) error {
-	exists, object, err := s.Exists(name)
+	return s.UpdateObjectContext(context.Background(), name, data)
+}
+
+func (s *Store) UpdateObjectContext(ctx context.Context, name string, data io.Reader) error {
+	exists, object, err := s.ExistsContext(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -305,27 +521,62 @@ func (s *Store) UpdateObject(name string, data io.Reader) error {
 		return objex.ErrObjectNotFound
 	}
 
-	return s.CreateObject(name, data, object.ContentType)
+	return s.PutObject(ctx, name, data, object.PutOptions())
 }
 
 func (s *Store) DeleteObject(name string) error {
+	return s.DeleteObjectContext(context.Background(), name)
+}
+
+func (s *Store) DeleteObjectContext(ctx context.Context, name string) error {
+	return s.DeleteObjectIf(ctx, name, objex.Conditions{})
+}
+
+// DeleteObjectIf deletes the object if cond holds. As on S3, only IfMatch
+// is supported. minio-go cannot send If-Match with a delete, so the ETag is
+// read and the object deleted in two requests: the check is best-effort and
+// not atomic, and an object replaced in between is deleted anyway.
+func (s *Store) DeleteObjectIf(ctx context.Context, name string, cond objex.Conditions) error {
 	if name == "" {
 		return objex.ErrInvalidObjectName
 	}
 
+	// S3 only supports If-Match on deletes.
+	if cond.IfNoneMatch != "" || cond.HasDates() {
+		return objex.ErrNotSupported
+	}
+
 	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
 		return err
 	}
 
+	// minio-go cannot send If-Match with RemoveObject, so it is checked
+	// against the object's current ETag first.
+	if cond.IfMatch != "" {
+		var current *objex.ObjectMetaData
+		info, err := s.client.StatObject(ctx, bucketName, fileName, minio.StatObjectOptions{})
+		err = toError("DeleteObject", bucketName, fileName, err)
+		if err == nil {
+			current = toMetaData(info)
+		} else if !errors.Is(err, objex.ErrObjectNotFound) {
+			return err
+		}
+
+		err = cond.Check(current)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.client.RemoveObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		fileName,
 		minio.RemoveObjectOptions{},
 	)
 
-	standardErr := ToStandardError(err)
+	standardErr := toError("DeleteObject", bucketName, fileName, err)
 	if standardErr != nil {
 		return standardErr
 	}
@@ -334,17 +585,18 @@ func (s *Store) DeleteObject(name string) error {
 }
 
 func (s *Store) ListObjects(name string) ([]*objex.ObjectMetaData, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	return s.ListObjectsContext(context.Background(), name)
+}
 
-	bucketName := s.bucket
-	if bucketName == "" {
-		bucketName = name
-	}
-	if bucketName == "" {
-		return nil, objex.ErrInvalidBucketName
+func (s *Store) ListObjectsContext(ctx context.Context, name string) ([]*objex.ObjectMetaData, error) {
+	bucketName, err := s.listBucket(name)
+	if err != nil {
+		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	objectChannel := s.client.ListObjects(
 		ctx,
 		bucketName,
@@ -356,7 +608,7 @@ func (s *Store) ListObjects(name string) ([]*objex.ObjectMetaData, error) {
 	var objects []*objex.ObjectMetaData
 	for object := range objectChannel {
 		if object.Err != nil {
-			return nil, ToStandardError(object.Err)
+			return nil, toError("ListObjects", bucketName, "", object.Err)
 		}
 
 		if object.Key == "" {
@@ -375,73 +627,194 @@ func (s *Store) ListObjects(name string) ([]*objex.ObjectMetaData, error) {
 	return objects, nil
 }
 
+// listBucket returns the bucket a listing is for: name, or the current
+// bucket when name is empty.
+func (s *Store) listBucket(name string) (string, error) {
+	if name == "" {
+		name = s.bucket
+	}
+	if name == "" {
+		return "", objex.ErrInvalidBucketName
+	}
+	return name, nil
+}
+
+func (s *Store) ListObjectsPage(ctx context.Context, name string, opts objex.ListOptions) (*objex.ListResult, error) {
+	bucketName, err := s.listBucket(name)
+	if err != nil {
+		return nil, err
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = objex.DefaultMaxKeys
+	}
+	after := max(opts.StartAfter, opts.ContinuationToken)
+
+	// Cancelling stops the listing goroutine once the page is full.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// minio-go groups keys on the server only for "/". Other delimiters
+	// are grouped here, from a recursive listing.
+	objectChannel := s.client.ListObjects(
+		ctx,
+		bucketName,
+		minio.ListObjectsOptions{
+			Prefix:     opts.Prefix,
+			StartAfter: after,
+			MaxKeys:    maxKeys,
+			Recursive:  opts.Delimiter != "/",
+		},
+	)
+
+	// As with objex.PageObjects, the continuation token is the last key
+	// or common prefix returned.
+	result := &objex.ListResult{}
+	last := ""
+	for object := range objectChannel {
+		if object.Err != nil {
+			return nil, toError("ListObjectsPage", bucketName, "", object.Err)
+		}
+
+		key := object.Key
+		commonPrefix := ""
+		if opts.Delimiter != "" && strings.HasPrefix(key, opts.Prefix) {
+			i := strings.Index(key[len(opts.Prefix):], opts.Delimiter)
+			if i >= 0 {
+				commonPrefix = key[:len(opts.Prefix)+i+len(opts.Delimiter)]
+			}
+		}
+
+		switch {
+		case key == "":
+			continue
+		case commonPrefix == "" && key <= after:
+			continue
+		case commonPrefix != "" && (commonPrefix == last || commonPrefix == after):
+			continue
+		}
+
+		if len(result.Objects)+len(result.CommonPrefixes) == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+
+		if commonPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+			last = commonPrefix
+		} else {
+			result.Objects = append(result.Objects, toMetaData(object))
+			last = key
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Store) Objects(ctx context.Context, name string, opts objex.ListOptions) iter.Seq2[*objex.ObjectMetaData, error] {
+	return func(yield func(*objex.ObjectMetaData, error) bool) {
+		bucketName, err := s.listBucket(name)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Cancelling stops the listing goroutine when the caller breaks.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		objectChannel := s.client.ListObjects(
+			ctx,
+			bucketName,
+			minio.ListObjectsOptions{
+				Prefix:     opts.Prefix,
+				StartAfter: max(opts.StartAfter, opts.ContinuationToken),
+				MaxKeys:    opts.MaxKeys,
+				Recursive:  true,
+			},
+		)
+
+		for object := range objectChannel {
+			if object.Err != nil {
+				yield(nil, toError("Objects", bucketName, "", object.Err))
+				return
+			}
+
+			if object.Key == "" {
+				continue
+			}
+
+			if !yield(toMetaData(object), nil) {
+				return
+			}
+		}
+	}
+}
+
 func (s *Store) Exists(name string) (bool, *objex.ObjectMetaData, error) {
+	return s.ExistsContext(context.Background(), name)
+}
+
+func (s *Store) ExistsContext(ctx context.Context, name string) (bool, *objex.ObjectMetaData, error) {
 	bucketName, name, err := objex.SplitPath(s.bucket, name)
 	if err != nil {
 		return false, nil, err
 	}
 
 	objectItem, err := s.client.StatObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		name,
 		minio.StatObjectOptions{},
 	)
 
 	if err != nil {
-		standardErr := ToStandardError(err)
-		if standardErr == objex.ErrObjectNotFound {
+		standardErr := toError("Exists", bucketName, name, err)
+		if errors.Is(standardErr, objex.ErrObjectNotFound) {
 			return false, nil, nil
 		}
 
 		return false, nil, standardErr
 	}
 
-	metadata := &objex.ObjectMetaData{
-		Key:          objectItem.Key,
-		LastModified: objectItem.LastModified.String(),
-		ETag:         objectItem.ETag,
-		Size:         objectItem.Size,
-		ContentType:  objectItem.ContentType,
-	}
-
-	return true, metadata, nil
+	return true, toMetaData(objectItem), nil
 }
 
 func (s *Store) Metadata(objectName string) (*objex.ObjectMetaData, error) {
+	return s.MetadataContext(context.Background(), objectName)
+}
+
+func (s *Store) MetadataContext(ctx context.Context, objectName string) (*objex.ObjectMetaData, error) {
 	bucketName, objectName, err := objex.SplitPath(s.bucket, objectName)
 	if err != nil {
 		return nil, err
 	}
 
 	objectItem, err := s.client.StatObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		objectName,
 		minio.StatObjectOptions{},
 	)
 
 	if err != nil {
-		standardErr := ToStandardError(err)
-		if standardErr == objex.ErrObjectNotFound {
-			return nil, nil
-		}
-
-		return nil, standardErr
+		return nil, toError("Metadata", bucketName, objectName, err)
 	}
 
-	object := &objex.ObjectMetaData{
-		Key:          objectItem.Key,
-		LastModified: objectItem.LastModified.String(),
-		ETag:         objectItem.ETag,
-		Size:         objectItem.Size,
-		ContentType:  objectItem.ContentType,
-	}
-
-	return object, nil
+	return toMetaData(objectItem), nil
 }
 
 func (s *Store) CopyObject(src, dest string) error {
+	return s.CopyObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) CopyObjectContext(ctx context.Context, src, dest string) error {
+	return s.CopyObjectIf(ctx, src, dest, objex.CopyConditions{})
+}
+
+func (s *Store) CopyObjectIf(ctx context.Context, src, dest string, cond objex.CopyConditions) error {
 	if src == "" || dest == "" {
 		return objex.ErrInvalidObjectName
 	}
@@ -469,9 +842,18 @@ func (s *Store) CopyObject(src, dest string) error {
 		destKey = paths[1]
 	}
 
+	// CopyObject has no conditions on the destination.
+	if !cond.Destination.IsZero() {
+		return objex.ErrNotSupported
+	}
+
 	srcOpts := minio.CopySrcOptions{
-		Bucket: srcBucket,
-		Object: srcKey,
+		Bucket:               srcBucket,
+		Object:               srcKey,
+		MatchETag:            cond.Source.IfMatch,
+		NoMatchETag:          cond.Source.IfNoneMatch,
+		MatchModifiedSince:   cond.Source.IfModifiedSince,
+		MatchUnmodifiedSince: cond.Source.IfUnmodifiedSince,
 	}
 
 	destOpts := minio.CopyDestOptions{
@@ -479,21 +861,25 @@ func (s *Store) CopyObject(src, dest string) error {
 		Object: destKey,
 	}
 
-	_, err := s.client.CopyObject(context.Background(), destOpts, srcOpts)
+	_, err := s.client.CopyObject(ctx, destOpts, srcOpts)
 	if err != nil {
-		return ToStandardError(err)
+		return toError("CopyObject", srcBucket, srcKey, err)
 	}
 
 	return nil
 }
 
 func (s *Store) MoveObject(src, dest string) error {
-	err := s.CopyObject(src, dest)
+	return s.MoveObjectContext(context.Background(), src, dest)
+}
+
+func (s *Store) MoveObjectContext(ctx context.Context, src, dest string) error {
+	err := s.CopyObjectContext(ctx, src, dest)
 	if err != nil {
 		return err
 	}
 
-	err = s.DeleteObject(src)
+	err = s.DeleteObjectContext(ctx, src)
 	if err != nil {
 		return err
 	}
@@ -501,7 +887,41 @@ func (s *Store) MoveObject(src, dest string) error {
 	return nil
 }
 
+func (s *Store) PresignObject(ctx context.Context, method, name string, expires time.Duration) (string, error) {
+	if name == "" {
+		return "", objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return "", err
+	}
+
+	var presigned *url.URL
+	switch method {
+	case http.MethodGet:
+		presigned, err = s.client.PresignedGetObject(ctx, bucketName, fileName, expires, nil)
+	case http.MethodPut:
+		presigned, err = s.client.PresignedPutObject(ctx, bucketName, fileName, expires)
+	case http.MethodHead:
+		presigned, err = s.client.PresignedHeadObject(ctx, bucketName, fileName, expires, nil)
+	case http.MethodDelete:
+		presigned, err = s.client.Presign(ctx, method, bucketName, fileName, expires, nil)
+	default:
+		return "", objex.ErrNotSupported
+	}
+	if err != nil {
+		return "", toError("PresignObject", bucketName, fileName, err)
+	}
+
+	return presigned.String(), nil
+}
+
 func (s *Store) CleanUp() error {
-	log.Println("[Objex Minio] CleanUp called — no action needed")
+	return s.CleanUpContext(context.Background())
+}
+
+func (s *Store) CleanUpContext(ctx context.Context) error {
+	s.logger.DebugContext(ctx, "nothing to clean up")
 	return nil
 }
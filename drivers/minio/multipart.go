@@ -0,0 +1,186 @@
+package minio
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/minio/minio-go/v7"
+)
+
+func (s *Store) CreateMultipartUpload(ctx context.Context, name string, opts objex.PutOptions) (string, error) {
+	if name == "" {
+		return "", objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return "", err
+	}
+
+	core := minio.Core{Client: s.client}
+	uploadID, err := core.NewMultipartUpload(ctx, bucketName, fileName, putObjectOptions(opts))
+	if err != nil {
+		return "", toError("CreateMultipartUpload", bucketName, fileName, err)
+	}
+
+	return uploadID, nil
+}
+
+func (s *Store) UploadPart(ctx context.Context, name, uploadID string, partNumber int, data io.Reader) (objex.Part, error) {
+	if name == "" {
+		return objex.Part{}, objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return objex.Part{}, err
+	}
+
+	reader, size, err := objex.GetStreamSize(data)
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucketName, fileName, err)
+	}
+
+	core := minio.Core{Client: s.client}
+	part, err := core.PutObjectPart(
+		ctx,
+		bucketName,
+		fileName,
+		uploadID,
+		partNumber,
+		reader,
+		size,
+		minio.PutObjectPartOptions{},
+	)
+	if err != nil {
+		return objex.Part{}, toError("UploadPart", bucketName, fileName, err)
+	}
+
+	return objex.Part{
+		PartNumber:   partNumber,
+		ETag:         part.ETag,
+		Size:         size,
+		LastModified: time.Now().UTC().String(),
+	}, nil
+}
+
+func (s *Store) ListParts(ctx context.Context, name, uploadID string) ([]objex.Part, error) {
+	if name == "" {
+		return nil, objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return nil, err
+	}
+
+	core := minio.Core{Client: s.client}
+
+	var parts []objex.Part
+	marker := 0
+	for {
+		result, err := core.ListObjectParts(ctx, bucketName, fileName, uploadID, marker, 1000)
+		if err != nil {
+			return nil, toError("ListParts", bucketName, fileName, err)
+		}
+
+		for _, part := range result.ObjectParts {
+			parts = append(parts, objex.Part{
+				PartNumber:   part.PartNumber,
+				ETag:         part.ETag,
+				Size:         part.Size,
+				LastModified: part.LastModified.String(),
+			})
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (s *Store) CompleteMultipartUpload(ctx context.Context, name, uploadID string, parts []objex.Part) error {
+	if name == "" {
+		return objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+
+	// Like S3's InvalidPartOrder, parts must be listed once each, in
+	// ascending order. They are checked here rather than sorted, so every
+	// driver rejects the same lists.
+	if len(parts) == 0 {
+		return toError("CompleteMultipartUpload", bucketName, fileName, objex.ErrInvalidPart)
+	}
+
+	completed := make([]minio.CompletePart, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return toError("CompleteMultipartUpload", bucketName, fileName, objex.ErrInvalidPart)
+		}
+
+		completed = append(completed, minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	core := minio.Core{Client: s.client}
+	_, err = core.CompleteMultipartUpload(ctx, bucketName, fileName, uploadID, completed, minio.PutObjectOptions{})
+	return toError("CompleteMultipartUpload", bucketName, fileName, err)
+}
+
+func (s *Store) AbortMultipartUpload(ctx context.Context, name, uploadID string) error {
+	if name == "" {
+		return objex.ErrInvalidObjectName
+	}
+
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+
+	core := minio.Core{Client: s.client}
+	err = core.AbortMultipartUpload(ctx, bucketName, fileName, uploadID)
+	return toError("AbortMultipartUpload", bucketName, fileName, err)
+}
+
+func (s *Store) ListMultipartUploads(ctx context.Context, name, prefix string) ([]objex.MultipartUpload, error) {
+	bucketName := name
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+	if bucketName == "" {
+		return nil, objex.ErrInvalidBucketName
+	}
+
+	core := minio.Core{Client: s.client}
+
+	var uploads []objex.MultipartUpload
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		result, err := core.ListMultipartUploads(ctx, bucketName, prefix, keyMarker, uploadIDMarker, "", 1000)
+		if err != nil {
+			return nil, toError("ListMultipartUploads", bucketName, "", err)
+		}
+
+		for _, upload := range result.Uploads {
+			uploads = append(uploads, objex.MultipartUpload{
+				Key:       upload.Key,
+				UploadID:  upload.UploadID,
+				Initiated: upload.Initiated.String(),
+			})
+		}
+
+		if !result.IsTruncated {
+			return uploads, nil
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+}
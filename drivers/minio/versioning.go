@@ -0,0 +1,150 @@
+package minio
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/minio/minio-go/v7"
+)
+
+func (s *Store) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
+	bucketName := name
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+	if bucketName == "" {
+		return objex.ErrInvalidBucketName
+	}
+
+	config := minio.BucketVersioningConfiguration{Status: minio.Suspended}
+	if enabled {
+		config.Status = minio.Enabled
+	}
+
+	err := s.client.SetBucketVersioning(ctx, bucketName, config)
+	return toError("SetBucketVersioning", bucketName, "", err)
+}
+
+func (s *Store) BucketVersioning(ctx context.Context, name string) (objex.VersioningStatus, error) {
+	bucketName := name
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+	if bucketName == "" {
+		return objex.VersioningOff, objex.ErrInvalidBucketName
+	}
+
+	config, err := s.client.GetBucketVersioning(ctx, bucketName)
+	if err != nil {
+		return objex.VersioningOff, toError("BucketVersioning", bucketName, "", err)
+	}
+	return objex.VersioningStatus(config.Status), nil
+}
+
+func (s *Store) ListObjectVersions(ctx context.Context, name, prefix string) ([]objex.ObjectVersion, error) {
+	bucketName := name
+	if bucketName == "" {
+		bucketName = s.bucket
+	}
+	if bucketName == "" {
+		return nil, objex.ErrInvalidBucketName
+	}
+
+	objectCh := s.client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	type listed struct {
+		version  objex.ObjectVersion
+		modified time.Time
+	}
+	var all []listed
+	for object := range objectCh {
+		if object.Err != nil {
+			return nil, toError("ListObjectVersions", bucketName, "", object.Err)
+		}
+
+		version := objex.ObjectVersion{
+			Key:            object.Key,
+			VersionID:      object.VersionID,
+			IsLatest:       object.IsLatest,
+			IsDeleteMarker: object.IsDeleteMarker,
+		}
+		if !object.IsDeleteMarker {
+			version.Size = object.Size
+			version.ETag = object.ETag
+		}
+		all = append(all, listed{version: version, modified: object.LastModified})
+	}
+
+	slices.SortStableFunc(all, func(a, b listed) int {
+		if c := strings.Compare(a.version.Key, b.version.Key); c != 0 {
+			return c
+		}
+		if a.version.IsLatest != b.version.IsLatest {
+			if a.version.IsLatest {
+				return -1
+			}
+			return 1
+		}
+		return b.modified.Compare(a.modified)
+	})
+
+	versions := make([]objex.ObjectVersion, len(all))
+	for i, l := range all {
+		versions[i] = l.version
+		versions[i].LastModified = l.modified.Format(time.RFC3339)
+	}
+	return versions, nil
+}
+
+func (s *Store) OpenObjectVersion(ctx context.Context, name, versionID string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if versionID == "" {
+		return nil, nil, objex.ErrVersionNotFound
+	}
+
+	body, meta, err := s.openObject(ctx, bucketName, fileName, versionID, opts)
+	if err != nil {
+		return nil, nil, toError("OpenObjectVersion", bucketName, fileName, err)
+	}
+	return body, meta, nil
+}
+
+func (s *Store) DeleteObjectVersion(ctx context.Context, name, versionID string) error {
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+	if versionID == "" {
+		return objex.ErrVersionNotFound
+	}
+
+	err = s.client.RemoveObject(ctx, bucketName, fileName, minio.RemoveObjectOptions{VersionID: versionID})
+	return toError("DeleteObjectVersion", bucketName, fileName, err)
+}
+
+func (s *Store) RestoreObjectVersion(ctx context.Context, name, versionID string) error {
+	bucketName, fileName, err := objex.SplitPath(s.bucket, name)
+	if err != nil {
+		return err
+	}
+	if versionID == "" {
+		return objex.ErrVersionNotFound
+	}
+
+	_, err = s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucketName, Object: fileName},
+		minio.CopySrcOptions{Bucket: bucketName, Object: fileName, VersionID: versionID},
+	)
+	return toError("RestoreObjectVersion", bucketName, fileName, err)
+}
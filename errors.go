@@ -0,0 +1,168 @@
+package objex
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Error describes a failed storage operation. Kind is one of the Err*
+// sentinels, so errors.Is(err, ErrObjectNotFound) keeps working, while
+// errors.As gives access to the details. errors.Is and errors.As also see
+// through to the cause in Err, such as context.Canceled.
+type Error struct {
+	// Kind is the Err* sentinel for the failure, or nil if it has none.
+	Kind error
+	// Op is the Store method that failed, such as "PutObject".
+	Op     string
+	Bucket string
+	Key    string
+	// Code is the provider's error code, such as "NoSuchKey" on S3.
+	Code string
+	// StatusCode is the HTTP status the provider answered with, or zero.
+	StatusCode int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("objex")
+	if e.Op != "" {
+		b.WriteString(" " + e.Op)
+	}
+	switch {
+	case e.Bucket != "" && e.Key != "":
+		b.WriteString(" " + e.Bucket + "/" + e.Key)
+	case e.Bucket != "" || e.Key != "":
+		b.WriteString(" " + e.Bucket + e.Key)
+	}
+	b.WriteString(":")
+
+	if e.Kind != nil {
+		b.WriteString(" " + e.Kind.Error())
+	}
+
+	var details []string
+	if e.Code != "" {
+		details = append(details, e.Code)
+	}
+	if e.StatusCode != 0 {
+		details = append(details, "HTTP "+strconv.Itoa(e.StatusCode))
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+
+	if e.Err != nil && e.Err != e.Kind {
+		if e.Kind != nil {
+			b.WriteString(":")
+		}
+		b.WriteString(" " + e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil && e.Err != e.Kind {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+var kinds = []error{
+	ErrUnknownDriver,
+	ErrInvalidEndpoint,
+	ErrInvalidAccessKey,
+	ErrInvalidSecretKey,
+	ErrClientInit,
+	ErrBucketNotFound,
+	ErrInvalidBucketName,
+	ErrObjectNotFound,
+	ErrAccessDenied,
+	ErrBucketNotEmpty,
+	ErrPreconditionFailed,
+	ErrBucketAlreadyExists,
+	ErrInvalidObjectName,
+	ErrInvalidFile,
+	ErrInvalidRange,
+	ErrNotSupported,
+	ErrUploadNotFound,
+	ErrInvalidPart,
+	ErrThrottled,
+	ErrUnavailable,
+	ErrVersionNotFound,
+}
+
+// KindOf returns the Err* sentinel err matches, or nil if it matches none.
+func KindOf(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// S3ErrorKind returns the Err* sentinel for an S3 error code, falling back
+// to the HTTP status for responses without one, such as HEAD requests. It
+// returns nil for codes that have no sentinel.
+func S3ErrorKind(code string, statusCode int) error {
+	switch code {
+	case "NoSuchKey", "NotFound":
+		return ErrObjectNotFound
+	case "NoSuchBucket":
+		return ErrBucketNotFound
+	case "BucketNotEmpty":
+		return ErrBucketNotEmpty
+	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou":
+		return ErrBucketAlreadyExists
+	case "InvalidBucketName":
+		return ErrInvalidBucketName
+	case "KeyTooLongError":
+		return ErrInvalidObjectName
+	case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		return ErrAccessDenied
+	case "InvalidRange":
+		return ErrInvalidRange
+	case "NoSuchUpload":
+		return ErrUploadNotFound
+	case "NoSuchVersion":
+		return ErrVersionNotFound
+	case "InvalidPart", "InvalidPartOrder", "EntityTooSmall":
+		return ErrInvalidPart
+	case "PreconditionFailed", "NotModified", "ConditionalRequestConflict":
+		return ErrPreconditionFailed
+	case "NotImplemented":
+		return ErrNotSupported
+	case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequests":
+		return ErrThrottled
+	case "InternalError", "ServiceUnavailable", "RequestTimeout":
+		return ErrUnavailable
+	}
+
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrObjectNotFound
+	case http.StatusForbidden:
+		return ErrAccessDenied
+	case http.StatusNotModified, http.StatusPreconditionFailed:
+		// Reads answer a failed If-None-Match or If-Modified-Since with
+		// 304 Not Modified.
+		return ErrPreconditionFailed
+	case http.StatusRequestedRangeNotSatisfiable:
+		return ErrInvalidRange
+	case http.StatusNotImplemented:
+		return ErrNotSupported
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package objex_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+)
+
+func TestS3ErrorKind(t *testing.T) {
+	tests := []struct {
+		code   string
+		status int
+		want   error
+	}{
+		{"NoSuchKey", http.StatusNotFound, objex.ErrObjectNotFound},
+		{"NoSuchBucket", http.StatusNotFound, objex.ErrBucketNotFound},
+		{"BucketNotEmpty", http.StatusConflict, objex.ErrBucketNotEmpty},
+		{"BucketAlreadyOwnedByYou", http.StatusConflict, objex.ErrBucketAlreadyExists},
+		{"SignatureDoesNotMatch", http.StatusForbidden, objex.ErrAccessDenied},
+		{"NoSuchVersion", http.StatusNotFound, objex.ErrVersionNotFound},
+		{"InvalidPartOrder", http.StatusBadRequest, objex.ErrInvalidPart},
+		{"PreconditionFailed", http.StatusPreconditionFailed, objex.ErrPreconditionFailed},
+		{"SlowDown", http.StatusServiceUnavailable, objex.ErrThrottled},
+		{"InternalError", http.StatusInternalServerError, objex.ErrUnavailable},
+		// HEAD responses carry no code, so the status decides.
+		{"", http.StatusNotFound, objex.ErrObjectNotFound},
+		{"", http.StatusNotModified, objex.ErrPreconditionFailed},
+		{"", http.StatusRequestedRangeNotSatisfiable, objex.ErrInvalidRange},
+		{"", http.StatusTooManyRequests, objex.ErrThrottled},
+		{"", http.StatusBadGateway, objex.ErrUnavailable},
+		// A code without a sentinel does not fall back to the status.
+		{"MalformedXML", http.StatusBadRequest, nil},
+		{"", http.StatusBadRequest, nil},
+	}
+
+	for _, test := range tests {
+		got := objex.S3ErrorKind(test.code, test.status)
+		if got != test.want {
+			t.Errorf("S3ErrorKind(%q, %d): got %v, want %v", test.code, test.status, got, test.want)
+		}
+	}
+}
+
+func TestErrorError(t *testing.T) {
+	cause := errors.New("connection reset")
+
+	tests := []struct {
+		err  *objex.Error
+		want string
+	}{
+		{
+			&objex.Error{Kind: objex.ErrObjectNotFound, Op: "ReadObject", Bucket: "photos", Key: "cat.jpg"},
+			"objex ReadObject photos/cat.jpg: OBJECT_NOT_FOUND",
+		},
+		{
+			&objex.Error{Kind: objex.ErrObjectNotFound, Op: "ReadObject", Bucket: "photos", Key: "cat.jpg", Err: objex.ErrObjectNotFound},
+			"objex ReadObject photos/cat.jpg: OBJECT_NOT_FOUND",
+		},
+		{
+			&objex.Error{Kind: objex.ErrThrottled, Op: "PutObject", Key: "cat.jpg", Code: "SlowDown", StatusCode: 503, Err: cause},
+			"objex PutObject cat.jpg: THROTTLED (SlowDown, HTTP 503): connection reset",
+		},
+		{
+			&objex.Error{Op: "ListBuckets", Err: cause},
+			"objex ListBuckets: connection reset",
+		},
+		{
+			&objex.Error{Kind: objex.ErrBucketNotFound, Op: "SetBucket", Bucket: "photos"},
+			"objex SetBucket photos: BUCKET_NOT_FOUND",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Error(): got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{nil, nil},
+		{errors.New("boom"), nil},
+		{objex.ErrObjectNotFound, objex.ErrObjectNotFound},
+		{fmt.Errorf("reading: %w", objex.ErrAccessDenied), objex.ErrAccessDenied},
+		{&objex.Error{Kind: objex.ErrThrottled, Err: context.DeadlineExceeded}, objex.ErrThrottled},
+		{&objex.Error{Err: objex.ErrInvalidRange}, objex.ErrInvalidRange},
+		{&objex.Error{Err: context.Canceled}, nil},
+	}
+
+	for _, test := range tests {
+		if got := objex.KindOf(test.err); got != test.want {
+			t.Errorf("KindOf(%v): got %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+// timeoutError is a cause with details errors.As can reach through an
+// *objex.Error.
+type timeoutError struct{ after string }
+
+func (e *timeoutError) Error() string { return "timed out after " + e.after }
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := &timeoutError{after: "5s"}
+	err := fmt.Errorf("saving: %w", &objex.Error{Kind: objex.ErrUnavailable, Op: "PutObject", Err: cause})
+
+	// Unwrap() []error exposes both the kind and the cause.
+	if !errors.Is(err, objex.ErrUnavailable) {
+		t.Errorf("errors.Is(%v, ErrUnavailable) = false", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false", err)
+	}
+	if errors.Is(err, objex.ErrThrottled) {
+		t.Errorf("errors.Is(%v, ErrThrottled) = true", err)
+	}
+
+	var e *objex.Error
+	if !errors.As(err, &e) || e.Op != "PutObject" {
+		t.Errorf("errors.As(%v, *objex.Error): got %v", err, e)
+	}
+	var timeout *timeoutError
+	if !errors.As(err, &timeout) || timeout.after != "5s" {
+		t.Errorf("errors.As(%v, *timeoutError): got %v", err, timeout)
+	}
+
+	// A Kind repeated as Err is unwrapped once.
+	same := &objex.Error{Kind: objex.ErrObjectNotFound, Err: objex.ErrObjectNotFound}
+	if got := same.Unwrap(); len(got) != 1 {
+		t.Errorf("Unwrap(): got %v, want only the kind", got)
+	}
+	if got := (&objex.Error{}).Unwrap(); len(got) != 0 {
+		t.Errorf("Unwrap() of an empty Error: got %v, want nothing", got)
+	}
+}
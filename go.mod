@@ -1,3 +1,7 @@
 module github.com/brian-nunez/objex
 
-go 1.22.2
+go 1.23.0
+
+require github.com/brian-nunez/objex/drivers/memory v1.0.3
+
+replace github.com/brian-nunez/objex/drivers/memory => ./drivers/memory
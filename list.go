@@ -0,0 +1,94 @@
+package objex
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultMaxKeys is the page size used when ListOptions.MaxKeys is not set.
+// It matches the S3 ListObjectsV2 default.
+const DefaultMaxKeys = 1000
+
+// ListOptions filters and pages the results of ListObjectsPage.
+type ListOptions struct {
+	// Prefix limits the results to keys that begin with it.
+	Prefix string
+	// Delimiter groups keys that contain it after Prefix into a single
+	// entry in ListResult.CommonPrefixes, like folders.
+	Delimiter string
+	// StartAfter lists keys that sort after it.
+	StartAfter string
+	// MaxKeys caps the number of objects and common prefixes in one page.
+	MaxKeys int
+	// ContinuationToken resumes a listing from ListResult.NextContinuationToken.
+	ContinuationToken string
+}
+
+// ListResult is one page of a listing.
+type ListResult struct {
+	Objects               []*ObjectMetaData
+	CommonPrefixes        []string
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// PageObjects applies opts to a full set of objects and returns a single
+// page, for drivers that cannot filter on the backend. objects is sorted by
+// key in place. The continuation token is the last key or common prefix
+// returned.
+func PageObjects(objects []*ObjectMetaData, opts ListOptions) *ListResult {
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	after := opts.StartAfter
+	if opts.ContinuationToken > after {
+		after = opts.ContinuationToken
+	}
+
+	result := &ListResult{}
+	count := 0
+	last := ""
+	for _, object := range objects {
+		key := object.Key
+		if !strings.HasPrefix(key, opts.Prefix) || key <= after {
+			continue
+		}
+
+		commonPrefix := ""
+		if opts.Delimiter != "" {
+			i := strings.Index(key[len(opts.Prefix):], opts.Delimiter)
+			if i >= 0 {
+				commonPrefix = key[:len(opts.Prefix)+i+len(opts.Delimiter)]
+			}
+		}
+
+		// Keys under a common prefix that was already returned, on this
+		// page or a previous one, are skipped.
+		if commonPrefix != "" && (commonPrefix == last || commonPrefix == after) {
+			continue
+		}
+
+		if count == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+
+		if commonPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+			last = commonPrefix
+		} else {
+			result.Objects = append(result.Objects, object)
+			last = key
+		}
+		count++
+	}
+
+	return result
+}
@@ -0,0 +1,114 @@
+package objex_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+)
+
+func objectsNamed(keys ...string) []*objex.ObjectMetaData {
+	var objects []*objex.ObjectMetaData
+	for _, key := range keys {
+		objects = append(objects, &objex.ObjectMetaData{Key: key})
+	}
+	return objects
+}
+
+// page is what one PageObjects call returned, with objects and common
+// prefixes flattened to their names.
+type page struct {
+	objects   []string
+	prefixes  []string
+	truncated bool
+	token     string
+}
+
+func pageObjects(objects []*objex.ObjectMetaData, opts objex.ListOptions) page {
+	result := objex.PageObjects(objects, opts)
+
+	var p page
+	for _, object := range result.Objects {
+		p.objects = append(p.objects, object.Key)
+	}
+	p.prefixes = result.CommonPrefixes
+	p.truncated = result.IsTruncated
+	p.token = result.NextContinuationToken
+	return p
+}
+
+func wantPage(t *testing.T, name string, got, want page) {
+	t.Helper()
+
+	if !slices.Equal(got.objects, want.objects) || !slices.Equal(got.prefixes, want.prefixes) ||
+		got.truncated != want.truncated || got.token != want.token {
+		t.Errorf("%s: got %+v, want %+v", name, got, want)
+	}
+}
+
+func TestPageObjectsContinuationToken(t *testing.T) {
+	// Out of order, as PageObjects sorts them itself.
+	objects := objectsNamed("d", "a", "e", "c", "b")
+	opts := objex.ListOptions{MaxKeys: 2}
+
+	got := pageObjects(objects, opts)
+	wantPage(t, "first page", got, page{objects: []string{"a", "b"}, truncated: true, token: "b"})
+
+	opts.ContinuationToken = got.token
+	got = pageObjects(objects, opts)
+	wantPage(t, "second page", got, page{objects: []string{"c", "d"}, truncated: true, token: "d"})
+
+	opts.ContinuationToken = got.token
+	got = pageObjects(objects, opts)
+	wantPage(t, "last page", got, page{objects: []string{"e"}})
+
+	// StartAfter and ContinuationToken both skip ahead; the later one wins.
+	got = pageObjects(objects, objex.ListOptions{StartAfter: "c", ContinuationToken: "a"})
+	wantPage(t, "StartAfter after the token", got, page{objects: []string{"d", "e"}})
+}
+
+func TestPageObjectsDelimiter(t *testing.T) {
+	objects := objectsNamed("a.txt", "photos/1.jpg", "photos/2.jpg", "videos/x/1.mp4", "videos/y.mp4", "z.txt")
+
+	got := pageObjects(objects, objex.ListOptions{Delimiter: "/"})
+	wantPage(t, "top level", got, page{
+		objects:  []string{"a.txt", "z.txt"},
+		prefixes: []string{"photos/", "videos/"},
+	})
+
+	got = pageObjects(objects, objex.ListOptions{Prefix: "videos/", Delimiter: "/"})
+	wantPage(t, "under videos/", got, page{
+		objects:  []string{"videos/y.mp4"},
+		prefixes: []string{"videos/x/"},
+	})
+
+	// A common prefix counts as one key and is not repeated on the next
+	// page, even though more keys sort under it.
+	opts := objex.ListOptions{Delimiter: "/", MaxKeys: 2}
+	got = pageObjects(objects, opts)
+	wantPage(t, "first page", got, page{objects: []string{"a.txt"}, prefixes: []string{"photos/"}, truncated: true, token: "photos/"})
+
+	opts.ContinuationToken = got.token
+	got = pageObjects(objects, opts)
+	wantPage(t, "second page", got, page{objects: []string{"z.txt"}, prefixes: []string{"videos/"}})
+}
+
+func TestPageObjectsEmptyFinalPage(t *testing.T) {
+	// The last page is full, so there is nothing to continue with.
+	objects := objectsNamed("a", "b")
+	got := pageObjects(objects, objex.ListOptions{MaxKeys: 2})
+	wantPage(t, "full last page", got, page{objects: []string{"a", "b"}})
+
+	// Keys left under a prefix that was just returned do not truncate the
+	// page either.
+	objects = objectsNamed("a", "p/1", "p/2")
+	got = pageObjects(objects, objex.ListOptions{Delimiter: "/", MaxKeys: 2})
+	wantPage(t, "last page ending in a prefix", got, page{objects: []string{"a"}, prefixes: []string{"p/"}})
+
+	// A token past the last key gives an empty page.
+	got = pageObjects(objects, objex.ListOptions{ContinuationToken: "p/2"})
+	wantPage(t, "after the last key", got, page{})
+
+	got = pageObjects(nil, objex.ListOptions{})
+	wantPage(t, "no objects", got, page{})
+}
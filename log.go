@@ -0,0 +1,20 @@
+package objex
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DiscardLogger returns a logger that drops every record. Drivers use it
+// when their Config has no Logger, so they stay silent by default.
+func DiscardLogger() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+// discardHandler is slog.DiscardHandler, which needs Go 1.24.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
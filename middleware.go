@@ -0,0 +1,48 @@
+package objex
+
+// Middleware wraps a Store to add behaviour such as logging, metrics,
+// access checks or retries without changing the driver.
+type Middleware func(Store) Store
+
+// Chain wraps store in middleware. The first middleware is the outermost,
+// so it sees every call first and every result last.
+//
+// Optional interfaces, such as Presigner, MultipartUploader, Versioner and
+// CapabilityReporter, are found by looking through the middleware with
+// Unwrap, so their calls go straight to the first store that implements
+// them and skip any middleware outside it. Middleware that must see those
+// calls, to deny presigning say, implements the interface itself.
+func Chain(store Store, middleware ...Middleware) Store {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		store = middleware[i](store)
+	}
+	return store
+}
+
+// Passthrough forwards every StoreContext method to the store it embeds.
+// Middleware embeds it, overrides only the methods it cares about and
+// returns itself through Adapt, so the context-free Store methods run
+// through the overrides too:
+//
+//	type keepBuckets struct{ objex.Passthrough }
+//
+//	func (keepBuckets) DeleteBucketContext(context.Context, string) error {
+//		return objex.ErrAccessDenied
+//	}
+//
+//	func KeepBuckets(next objex.Store) objex.Store {
+//		return objex.Adapt(keepBuckets{objex.Passthrough{StoreContext: next}})
+//	}
+//
+// Calls a driver makes to itself, such as CreateObjectContext calling
+// PutObject, stay inside the driver and do not pass through the middleware.
+// Handles returned by Bucket come from the wrapped store and skip the
+// middleware unless it overrides Bucket, usually with WrapBucket.
+type Passthrough struct {
+	StoreContext
+}
+
+// Unwrap returns the wrapped store.
+func (p Passthrough) Unwrap() StoreContext {
+	return p.StoreContext
+}
@@ -0,0 +1,86 @@
+package objex_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+)
+
+// presigningStore is a memory store that counts the URLs it presigns.
+type presigningStore struct {
+	*memory.Store
+	presigned *int
+}
+
+func (s presigningStore) PresignObject(ctx context.Context, method, objectName string, expires time.Duration) (string, error) {
+	*s.presigned++
+	return "https://example.com/" + objectName, nil
+}
+
+type keepBuckets struct{ objex.Passthrough }
+
+func (keepBuckets) DeleteBucketContext(context.Context, string) error {
+	return objex.ErrAccessDenied
+}
+
+func KeepBuckets(next objex.Store) objex.Store {
+	return objex.Adapt(keepBuckets{objex.Passthrough{StoreContext: next}})
+}
+
+type denyPresign struct{ objex.Passthrough }
+
+func (denyPresign) PresignObject(context.Context, string, string, time.Duration) (string, error) {
+	return "", objex.ErrAccessDenied
+}
+
+func DenyPresign(next objex.Store) objex.Store {
+	return objex.Adapt(denyPresign{objex.Passthrough{StoreContext: next}})
+}
+
+func TestChainOptionalInterfaces(t *testing.T) {
+	mem, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var presigned int
+	driver := presigningStore{Store: mem, presigned: &presigned}
+	ctx := context.Background()
+
+	// KeepBuckets does not implement Presigner, so presigning skips it.
+	store := objex.Chain(driver, KeepBuckets)
+	wantErr(t, "DeleteBucket", store.DeleteBucket("photos"), objex.ErrAccessDenied)
+
+	presigner, ok := objex.AsPresigner(store)
+	if !ok {
+		t.Fatal("AsPresigner: not found through the middleware")
+	}
+	_, err = presigner.PresignObject(ctx, http.MethodGet, "cat.jpg", time.Minute)
+	if err != nil || presigned != 1 {
+		t.Errorf("PresignObject: got %v after %d presigned URLs, want the driver to presign", err, presigned)
+	}
+
+	// DenyPresign does, so it sees the call.
+	store = objex.Chain(driver, KeepBuckets, DenyPresign)
+	presigner, ok = objex.AsPresigner(store)
+	if !ok {
+		t.Fatal("AsPresigner: not found through the middleware")
+	}
+	_, err = presigner.PresignObject(ctx, http.MethodGet, "cat.jpg", time.Minute)
+	wantErr(t, "PresignObject", err, objex.ErrAccessDenied)
+	if presigned != 1 {
+		t.Errorf("PresignObject: the driver presigned %d URLs, want 1", presigned)
+	}
+}
+
+func wantErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Errorf("%s: got error %v, want %v", op, err, want)
+	}
+}
@@ -0,0 +1,53 @@
+package objex
+
+import (
+	"context"
+	"io"
+)
+
+// Part is one uploaded part of a multipart upload.
+type Part struct {
+	PartNumber   int
+	ETag         string
+	Size         int64
+	LastModified string
+}
+
+// MultipartUpload is an upload that has been started but not completed or
+// aborted.
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated string
+}
+
+// MultipartUploader is implemented by stores that expose multipart uploads,
+// so a large upload can be resumed part by part after a failure instead of
+// starting over.
+type MultipartUploader interface {
+	// CreateMultipartUpload starts an upload and returns its ID. opts apply
+	// to the object once the upload is completed.
+	CreateMultipartUpload(ctx context.Context, objectName string, opts PutOptions) (uploadID string, err error)
+	// UploadPart stores part partNumber (1 to 10000) of an upload.
+	// Uploading the same number again replaces the part. S3 requires every
+	// part but the last to be at least 5 MiB.
+	UploadPart(ctx context.Context, objectName, uploadID string, partNumber int, data io.Reader) (Part, error)
+	// ListParts returns the parts uploaded so far, in part number order.
+	ListParts(ctx context.Context, objectName, uploadID string) ([]Part, error)
+	// CompleteMultipartUpload assembles parts into the object. parts must
+	// be in strictly ascending part number order; a repeated or out of
+	// order part, or one whose ETag does not match the uploaded part, fails
+	// with ErrInvalidPart.
+	CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []Part) error
+	// AbortMultipartUpload discards an upload and its parts.
+	AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error
+	// ListMultipartUploads returns the in-progress uploads in bucketName, or
+	// the current bucket, whose keys start with prefix.
+	ListMultipartUploads(ctx context.Context, bucketName, prefix string) ([]MultipartUpload, error)
+}
+
+// AsMultipartUploader returns the MultipartUploader behind store, looking
+// through wrappers such as the one returned by Adapt.
+func AsMultipartUploader(store StoreContext) (MultipartUploader, bool) {
+	return as[MultipartUploader](store)
+}
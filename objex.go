@@ -1,8 +1,10 @@
 package objex
 
 import (
+	"context"
 	"errors"
 	"io"
+	"iter"
 )
 
 var (
@@ -20,6 +22,13 @@ var (
 	ErrBucketAlreadyExists = errors.New("BUCKET_ALREADY_EXISTS")
 	ErrInvalidObjectName   = errors.New("INVALID_OBJECT_NAME")
 	ErrInvalidFile         = errors.New("INVALID_FILE")
+	ErrInvalidRange        = errors.New("INVALID_RANGE")
+	ErrNotSupported        = errors.New("NOT_SUPPORTED")
+	ErrUploadNotFound      = errors.New("UPLOAD_NOT_FOUND")
+	ErrInvalidPart         = errors.New("INVALID_PART")
+	ErrThrottled           = errors.New("THROTTLED")
+	ErrUnavailable         = errors.New("SERVICE_UNAVAILABLE")
+	ErrVersionNotFound     = errors.New("VERSION_NOT_FOUND")
 )
 
 type Bucket struct {
@@ -28,15 +37,79 @@ type Bucket struct {
 }
 
 type ObjectMetaData struct {
-	Key          string
-	Size         int64
-	ContentType  string
-	ETag         string
-	LastModified string
+	Key                string
+	Size               int64
+	ContentType        string
+	ETag               string
+	LastModified       string
+	ContentDisposition string
+	ContentEncoding    string
+	CacheControl       string
+	ContentLanguage    string
+	// UserMetadata holds the custom key/value pairs stored with the object
+	// (x-amz-meta-* on S3). Keys are lower case. It is only filled in by
+	// OpenObject, Exists and Metadata, not by listings.
+	UserMetadata map[string]string
+	// ChecksumSHA256 and ChecksumCRC32C are the base64 encoded checksums
+	// of the object's data, when the driver stores them.
+	ChecksumSHA256 string
+	ChecksumCRC32C string
+	// VersionID is the version described, when the store reports one.
+	VersionID string
 }
 
-// TODO: write comments for each function
+// StoreContext is the context-aware form of Store. Every method takes a
+// context.Context that drivers pass through to the underlying SDK or file
+// operation, so deadlines and cancellation reach the backend.
+type StoreContext interface {
+	SetupContext(ctx context.Context) error
+	SetBucketContext(ctx context.Context, bucketName string) (found bool, err error)
+	SetRegionContext(ctx context.Context, region string) error
+	CreateBucketContext(ctx context.Context, bucketName string) error
+	DeleteBucketContext(ctx context.Context, bucketName string) error
+	ListBucketsContext(ctx context.Context) ([]Bucket, error)
+	CreateObjectContext(ctx context.Context, objectName string, data io.Reader, contentType string) error
+	// PutObject writes an object with the headers and user metadata in opts.
+	PutObject(ctx context.Context, objectName string, data io.Reader, opts PutOptions) error
+	ReadObjectContext(ctx context.Context, fileName string) ([]byte, error)
+	// OpenObject streams an object from the backend. The caller must close
+	// the returned reader. A missing object is reported as ErrObjectNotFound
+	// and an unsatisfiable opts.Range as ErrInvalidRange.
+	OpenObject(ctx context.Context, fileName string, opts GetOptions) (io.ReadCloser, *ObjectMetaData, error)
+	UpdateObjectContext(ctx context.Context, fileName string, data io.Reader) error
+	DeleteObjectContext(ctx context.Context, fileName string) error
+	// DeleteObjectIf deletes an object only if cond holds for it.
+	DeleteObjectIf(ctx context.Context, fileName string, cond Conditions) error
+	ListObjectsContext(ctx context.Context, bucketName string) ([]*ObjectMetaData, error)
+	// ListObjectsPage lists one page of bucketName, or of the current bucket
+	// when bucketName is empty, filtered by opts.
+	ListObjectsPage(ctx context.Context, bucketName string, opts ListOptions) (*ListResult, error)
+	// Objects lazily iterates over every object in bucketName, or the
+	// current bucket, in key order, fetching further pages only as the loop
+	// asks for them. Delimiter is ignored; use ListObjectsPage to browse
+	// common prefixes. Iteration stops after the first error.
+	Objects(ctx context.Context, bucketName string, opts ListOptions) iter.Seq2[*ObjectMetaData, error]
+	ExistsContext(ctx context.Context, fileName string) (bool, *ObjectMetaData, error)
+	MetadataContext(ctx context.Context, fileName string) (*ObjectMetaData, error)
+	CopyObjectContext(ctx context.Context, fileSource, fileDestination string) error
+	// CopyObjectIf copies an object only if cond holds for the source and
+	// the destination it replaces.
+	CopyObjectIf(ctx context.Context, fileSource, fileDestination string, cond CopyConditions) error
+	MoveObjectContext(ctx context.Context, fileSource, fileDestination string) error
+	CleanUpContext(ctx context.Context) error
+	HealthCheckContext(ctx context.Context) error
+	// Bucket returns a handle bound to bucketName. It shares the store's
+	// connection but not its current bucket, so SetBucket does not affect
+	// it and handles for different buckets can be used concurrently.
+	Bucket(bucketName string) BucketHandle
+}
+
+// Store is the interface every driver returns. It embeds StoreContext, so
+// implementing the context-free methods alone is no longer enough; stores
+// that only implement StoreContext get them from Adapt.
 type Store interface {
+	StoreContext
+
 	Setup() error
 	SetBucket(bucketName string) (found bool, err error)
 	SetRegion(region string) error
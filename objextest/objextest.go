@@ -0,0 +1,896 @@
+// Package objextest provides a conformance suite for objex.Store
+// implementations, so every driver agrees on the behaviour code written
+// against objex relies on.
+//
+// A driver runs it from one of its tests:
+//
+//	func TestConformance(t *testing.T) {
+//		objextest.Run(t, func(t *testing.T) objex.Store {
+//			store, err := filesystem.NewStore(filesystem.Config{BasePath: t.TempDir()})
+//			if err != nil {
+//				t.Fatal(err)
+//			}
+//			return store
+//		})
+//	}
+package objextest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brian-nunez/objex"
+)
+
+// Run runs every conformance case as a subtest. newStore is called once per
+// case and must return a working store; the suite creates the buckets it
+// needs, with names starting with "objextest-", and deletes them when the
+// case ends.
+func Run(t *testing.T, newStore func(t *testing.T) objex.Store) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := newStore(t)
+			bucket := newBucket(t, store)
+
+			_, err := store.SetBucket(bucket)
+			if err != nil {
+				t.Fatalf("SetBucket(%q): %v", bucket, err)
+			}
+
+			c.run(t, store, bucket)
+		})
+	}
+}
+
+var cases = []struct {
+	name string
+	run  func(t *testing.T, s objex.Store, bucket string)
+}{
+	{"CreateAndRead", testCreateAndRead},
+	{"Missing", testMissing},
+	{"NestedKeys", testNestedKeys},
+	{"UnsafeKeys", testUnsafeKeys},
+	{"EmptyObject", testEmptyObject},
+	{"Overwrite", testOverwrite},
+	{"Update", testUpdate},
+	{"Delete", testDelete},
+	{"Copy", testCopy},
+	{"Move", testMove},
+	{"ListPrefixDelimiter", testListPrefixDelimiter},
+	{"ListPagination", testListPagination},
+	{"ObjectsIterator", testObjectsIterator},
+	{"Ranges", testRanges},
+	{"Conditions", testConditions},
+	{"Multipart", testMultipart},
+	{"Versioning", testVersioning},
+	{"Presign", testPresign},
+	{"Capabilities", testCapabilities},
+	{"Buckets", testBuckets},
+	{"CanceledContext", testCanceledContext},
+	{"BucketHandles", testBucketHandles},
+}
+
+// newBucket creates an empty bucket with a unique name and removes it, with
+// anything left in it, when the test ends.
+func newBucket(t *testing.T, s objex.Store) string {
+	t.Helper()
+
+	id := make([]byte, 6)
+	_, err := rand.Read(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket := "objextest-" + hex.EncodeToString(id)
+
+	err = s.CreateBucket(bucket)
+	if err != nil {
+		t.Fatalf("CreateBucket(%q): %v", bucket, err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		handle := s.Bucket(bucket)
+
+		objects, err := handle.ListObjects(ctx)
+		if err != nil {
+			t.Logf("cleanup: listing %s: %v", bucket, err)
+		}
+		for _, object := range objects {
+			err := handle.DeleteObject(ctx, object.Key)
+			if err != nil {
+				t.Logf("cleanup: deleting %s/%s: %v", bucket, object.Key, err)
+			}
+		}
+		err = s.DeleteBucket(bucket)
+		if err != nil {
+			t.Logf("cleanup: deleting bucket %s: %v", bucket, err)
+		}
+	})
+
+	return bucket
+}
+
+func put(t *testing.T, s objex.Store, key, body string, opts objex.PutOptions) {
+	t.Helper()
+
+	err := s.PutObject(context.Background(), key, strings.NewReader(body), opts)
+	if err != nil {
+		t.Fatalf("PutObject(%q): %v", key, err)
+	}
+}
+
+func read(t *testing.T, s objex.Store, key string) string {
+	t.Helper()
+
+	data, err := s.ReadObject(key)
+	if err != nil {
+		t.Fatalf("ReadObject(%q): %v", key, err)
+	}
+	return string(data)
+}
+
+func metadata(t *testing.T, s objex.Store, key string) *objex.ObjectMetaData {
+	t.Helper()
+
+	meta, err := s.Metadata(key)
+	if err != nil {
+		t.Fatalf("Metadata(%q): %v", key, err)
+	}
+	return meta
+}
+
+func keys(objects []*objex.ObjectMetaData) []string {
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	return keys
+}
+
+func wantErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Errorf("%s: got error %v, want %v", op, err, want)
+	}
+}
+
+func wantKeys(t *testing.T, op string, got, want []string) {
+	t.Helper()
+
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: got keys %q, want %q", op, got, want)
+	}
+}
+
+func testCreateAndRead(t *testing.T, s objex.Store, bucket string) {
+	err := s.CreateObject("hello.txt", strings.NewReader("hello world"), "text/plain")
+	if err != nil {
+		t.Fatalf("CreateObject: %v", err)
+	}
+
+	if got := read(t, s, "hello.txt"); got != "hello world" {
+		t.Errorf("ReadObject: got %q, want %q", got, "hello world")
+	}
+
+	meta := metadata(t, s, "hello.txt")
+	if meta.Key != "hello.txt" {
+		t.Errorf("Metadata: got key %q, want %q", meta.Key, "hello.txt")
+	}
+	if meta.Size != 11 {
+		t.Errorf("Metadata: got size %d, want 11", meta.Size)
+	}
+	if meta.ContentType != "text/plain" {
+		t.Errorf("Metadata: got content type %q, want %q", meta.ContentType, "text/plain")
+	}
+	if meta.ETag == "" || strings.Contains(meta.ETag, `"`) {
+		t.Errorf("Metadata: got ETag %q, want it unquoted", meta.ETag)
+	}
+	if meta.LastModified == "" {
+		t.Error("Metadata: empty LastModified")
+	}
+
+	found, meta, err := s.Exists("hello.txt")
+	if err != nil || !found || meta == nil {
+		t.Errorf("Exists: got %v, %v, %v, want true with metadata", found, meta, err)
+	}
+}
+
+func testMissing(t *testing.T, s objex.Store, bucket string) {
+	data, err := s.ReadObject("missing.txt")
+	wantErr(t, "ReadObject", err, objex.ErrObjectNotFound)
+	if data != nil {
+		t.Errorf("ReadObject: got %q for a missing object", data)
+	}
+
+	_, _, err = s.OpenObject(context.Background(), "missing.txt", objex.GetOptions{})
+	wantErr(t, "OpenObject", err, objex.ErrObjectNotFound)
+
+	_, err = s.Metadata("missing.txt")
+	wantErr(t, "Metadata", err, objex.ErrObjectNotFound)
+
+	found, _, err := s.Exists("missing.txt")
+	if err != nil || found {
+		t.Errorf("Exists: got %v, %v, want false, nil", found, err)
+	}
+
+	err = s.CopyObject("missing.txt", "copy.txt")
+	wantErr(t, "CopyObject", err, objex.ErrObjectNotFound)
+
+	err = s.UpdateObject("missing.txt", strings.NewReader("data"))
+	wantErr(t, "UpdateObject", err, objex.ErrObjectNotFound)
+}
+
+func testNestedKeys(t *testing.T, s objex.Store, bucket string) {
+	want := []string{"a/b/c.txt", "a/d.txt", "a-b.txt", "dir with space/ü.txt"}
+	for _, key := range want {
+		put(t, s, key, key, objex.PutOptions{})
+	}
+
+	for _, key := range want {
+		if got := read(t, s, key); got != key {
+			t.Errorf("ReadObject(%q): got %q", key, got)
+		}
+	}
+
+	objects, err := s.ListObjects(bucket)
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	slices.Sort(want)
+	wantKeys(t, "ListObjects", keys(objects), want)
+
+	// "a" and "a/b" are only prefixes of other keys, not objects.
+	for _, prefix := range []string{"a", "a/b"} {
+		found, _, err := s.Exists(prefix)
+		if err != nil || found {
+			t.Errorf("Exists(%q): got %v, %v, want false, nil", prefix, found, err)
+		}
+
+		err = s.CopyObject(prefix, "copy.txt")
+		wantErr(t, fmt.Sprintf("CopyObject(%q)", prefix), err, objex.ErrObjectNotFound)
+	}
+}
+
+// testUnsafeKeys writes keys that would leave the bucket, or reach the
+// filesystem driver's hidden directory, if used as file paths. A store may
+// reject them with ErrInvalidObjectName; if it accepts one, the object must
+// read back and list under exactly that key.
+func testUnsafeKeys(t *testing.T, s objex.Store, bucket string) {
+	unsafe := []string{
+		"../escape.txt",
+		"a/../../escape.txt",
+		"/absolute.txt",
+		"a//b.txt",
+		"./dot.txt",
+		"dir/",
+		"a/.objex/hidden.txt",
+	}
+
+	var accepted []string
+	for _, key := range unsafe {
+		err := s.PutObject(context.Background(), key, strings.NewReader(key), objex.PutOptions{})
+		if err != nil {
+			wantErr(t, fmt.Sprintf("PutObject(%q)", key), err, objex.ErrInvalidObjectName)
+			continue
+		}
+		accepted = append(accepted, key)
+
+		if got := read(t, s, key); got != key {
+			t.Errorf("ReadObject(%q): got %q", key, got)
+		}
+	}
+
+	objects, err := s.ListObjects(bucket)
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	slices.Sort(accepted)
+	wantKeys(t, "ListObjects", keys(objects), accepted)
+}
+
+func testEmptyObject(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "empty", "", objex.PutOptions{})
+
+	if got := read(t, s, "empty"); got != "" {
+		t.Errorf("ReadObject: got %q, want empty", got)
+	}
+	if meta := metadata(t, s, "empty"); meta.Size != 0 {
+		t.Errorf("Metadata: got size %d, want 0", meta.Size)
+	}
+
+	// The zero Range is the whole object, even when that is nothing.
+	body, _, err := s.OpenObject(context.Background(), "empty", objex.GetOptions{Range: &objex.Range{}})
+	if err != nil {
+		t.Fatalf("OpenObject with the zero Range: %v", err)
+	}
+	body.Close()
+}
+
+func testOverwrite(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "doc.txt", "first", objex.PutOptions{})
+	first := metadata(t, s, "doc.txt")
+
+	put(t, s, "doc.txt", "second version", objex.PutOptions{})
+	second := metadata(t, s, "doc.txt")
+
+	if got := read(t, s, "doc.txt"); got != "second version" {
+		t.Errorf("ReadObject: got %q, want %q", got, "second version")
+	}
+	if first.ETag == second.ETag {
+		t.Errorf("ETag %q did not change when the object did", first.ETag)
+	}
+}
+
+func testUpdate(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "config.json", `{"v":1}`, objex.PutOptions{
+		ContentType:  "application/json",
+		UserMetadata: map[string]string{"owner": "objex"},
+	})
+
+	err := s.UpdateObject("config.json", strings.NewReader(`{"v":2}`))
+	if err != nil {
+		t.Fatalf("UpdateObject: %v", err)
+	}
+
+	if got := read(t, s, "config.json"); got != `{"v":2}` {
+		t.Errorf("ReadObject: got %q", got)
+	}
+	meta := metadata(t, s, "config.json")
+	if meta.ContentType != "application/json" {
+		t.Errorf("Metadata: got content type %q, want it kept", meta.ContentType)
+	}
+	if meta.UserMetadata["owner"] != "objex" {
+		t.Errorf("Metadata: got user metadata %v, want it kept", meta.UserMetadata)
+	}
+}
+
+func testDelete(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "dir/gone.txt", "bye", objex.PutOptions{})
+
+	err := s.DeleteObject("dir/gone.txt")
+	if err != nil {
+		t.Fatalf("DeleteObject: %v", err)
+	}
+
+	found, _, err := s.Exists("dir/gone.txt")
+	if err != nil || found {
+		t.Errorf("Exists after delete: got %v, %v, want false, nil", found, err)
+	}
+
+	err = s.DeleteObject("dir/gone.txt")
+	if err != nil {
+		t.Errorf("DeleteObject of a missing object: got %v, want nil", err)
+	}
+}
+
+func testCopy(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "src file.txt", "copy me", objex.PutOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"origin": "test"},
+	})
+
+	err := s.CopyObject("src file.txt", "copies/dst ü.txt")
+	if err != nil {
+		t.Fatalf("CopyObject: %v", err)
+	}
+
+	if got := read(t, s, "copies/dst ü.txt"); got != "copy me" {
+		t.Errorf("ReadObject copy: got %q", got)
+	}
+	if got := read(t, s, "src file.txt"); got != "copy me" {
+		t.Errorf("ReadObject source: got %q", got)
+	}
+
+	meta := metadata(t, s, "copies/dst ü.txt")
+	if meta.ContentType != "text/plain" {
+		t.Errorf("copy: got content type %q, want %q", meta.ContentType, "text/plain")
+	}
+	if meta.UserMetadata["origin"] != "test" {
+		t.Errorf("copy: got user metadata %v, want it copied", meta.UserMetadata)
+	}
+}
+
+func testMove(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "old/name.txt", "moving", objex.PutOptions{})
+
+	err := s.MoveObject("old/name.txt", "new/name.txt")
+	if err != nil {
+		t.Fatalf("MoveObject: %v", err)
+	}
+
+	if got := read(t, s, "new/name.txt"); got != "moving" {
+		t.Errorf("ReadObject: got %q", got)
+	}
+	found, _, err := s.Exists("old/name.txt")
+	if err != nil || found {
+		t.Errorf("Exists source: got %v, %v, want false, nil", found, err)
+	}
+}
+
+func testListPrefixDelimiter(t *testing.T, s objex.Store, bucket string) {
+	for _, key := range []string{"a.txt", "photos/1.jpg", "photos/2.jpg", "photos/2024/3.jpg", "videos/1.mp4"} {
+		put(t, s, key, key, objex.PutOptions{})
+	}
+	ctx := context.Background()
+
+	page, err := s.ListObjectsPage(ctx, bucket, objex.ListOptions{Delimiter: "/"})
+	if err != nil {
+		t.Fatalf("ListObjectsPage: %v", err)
+	}
+	wantKeys(t, "root objects", keys(page.Objects), []string{"a.txt"})
+	wantKeys(t, "root prefixes", page.CommonPrefixes, []string{"photos/", "videos/"})
+
+	page, err = s.ListObjectsPage(ctx, bucket, objex.ListOptions{Prefix: "photos/", Delimiter: "/"})
+	if err != nil {
+		t.Fatalf("ListObjectsPage: %v", err)
+	}
+	wantKeys(t, "photos/ objects", keys(page.Objects), []string{"photos/1.jpg", "photos/2.jpg"})
+	wantKeys(t, "photos/ prefixes", page.CommonPrefixes, []string{"photos/2024/"})
+
+	page, err = s.ListObjectsPage(ctx, bucket, objex.ListOptions{Prefix: "photos/2"})
+	if err != nil {
+		t.Fatalf("ListObjectsPage: %v", err)
+	}
+	wantKeys(t, "photos/2 objects", keys(page.Objects), []string{"photos/2.jpg", "photos/2024/3.jpg"})
+}
+
+func testListPagination(t *testing.T, s objex.Store, bucket string) {
+	want := []string{"k1", "k2", "k3", "k4", "k5"}
+	for _, key := range want {
+		put(t, s, key, key, objex.PutOptions{})
+	}
+
+	var got []string
+	opts := objex.ListOptions{MaxKeys: 2}
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("ListObjectsPage: too many pages")
+		}
+
+		page, err := s.ListObjectsPage(context.Background(), bucket, opts)
+		if err != nil {
+			t.Fatalf("ListObjectsPage: %v", err)
+		}
+		if len(page.Objects) > 2 {
+			t.Errorf("ListObjectsPage: got %d objects, want at most 2", len(page.Objects))
+		}
+		got = append(got, keys(page.Objects)...)
+
+		if !page.IsTruncated {
+			break
+		}
+		if page.NextContinuationToken == "" {
+			t.Fatal("ListObjectsPage: truncated page without a continuation token")
+		}
+		opts.ContinuationToken = page.NextContinuationToken
+	}
+	wantKeys(t, "pages", got, want)
+}
+
+func testObjectsIterator(t *testing.T, s objex.Store, bucket string) {
+	for _, key := range []string{"logs/c", "logs/a", "logs/b/1", "other"} {
+		put(t, s, key, key, objex.PutOptions{})
+	}
+	ctx := context.Background()
+
+	var got []string
+	for object, err := range s.Objects(ctx, bucket, objex.ListOptions{Prefix: "logs/"}) {
+		if err != nil {
+			t.Fatalf("Objects: %v", err)
+		}
+		got = append(got, object.Key)
+	}
+	wantKeys(t, "Objects", got, []string{"logs/a", "logs/b/1", "logs/c"})
+
+	got = nil
+	for object, err := range s.Objects(ctx, bucket, objex.ListOptions{StartAfter: "logs/a"}) {
+		if err != nil {
+			t.Fatalf("Objects: %v", err)
+		}
+		got = append(got, object.Key)
+		if len(got) == 2 {
+			break
+		}
+	}
+	wantKeys(t, "Objects after logs/a", got, []string{"logs/b/1", "logs/c"})
+}
+
+func testRanges(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "digits", "0123456789", objex.PutOptions{})
+
+	tests := []struct {
+		rng  objex.Range
+		want string
+	}{
+		{objex.Range{Offset: 2, Length: 3}, "234"},
+		{objex.Range{Offset: 7}, "789"},
+		{objex.Range{Suffix: 4}, "6789"},
+		{objex.Range{Offset: 8, Length: 10}, "89"},
+	}
+	for _, test := range tests {
+		body, meta, err := s.OpenObject(context.Background(), "digits", objex.GetOptions{Range: &test.rng})
+		if err != nil {
+			t.Errorf("OpenObject(%+v): %v", test.rng, err)
+			continue
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Errorf("reading %+v: %v", test.rng, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("OpenObject(%+v): got %q, want %q", test.rng, data, test.want)
+		}
+		if meta.Size != int64(len(test.want)) {
+			t.Errorf("OpenObject(%+v): got size %d, want %d", test.rng, meta.Size, len(test.want))
+		}
+	}
+
+	_, _, err := s.OpenObject(context.Background(), "digits", objex.GetOptions{Range: &objex.Range{Offset: 20}})
+	wantErr(t, "OpenObject past the end", err, objex.ErrInvalidRange)
+}
+
+// skipUnsupported skips the rest of a test when the store reports that it
+// cannot do what was asked.
+func skipUnsupported(t *testing.T, err error) {
+	t.Helper()
+
+	if errors.Is(err, objex.ErrNotSupported) {
+		t.Skipf("not supported: %v", err)
+	}
+}
+
+func testConditions(t *testing.T, s objex.Store, bucket string) {
+	ctx := context.Background()
+	put(t, s, "manifest.json", "v1", objex.PutOptions{})
+	etag := metadata(t, s, "manifest.json").ETag
+
+	err := s.PutObject(ctx, "manifest.json", strings.NewReader("v2"), objex.PutOptions{
+		Conditions: objex.Conditions{IfMatch: `"not-the-etag"`},
+	})
+	skipUnsupported(t, err)
+	wantErr(t, "PutObject with a stale If-Match", err, objex.ErrPreconditionFailed)
+
+	err = s.PutObject(ctx, "manifest.json", strings.NewReader("v2"), objex.PutOptions{
+		Conditions: objex.Conditions{IfMatch: etag},
+	})
+	if err != nil {
+		t.Errorf("PutObject with a current If-Match: %v", err)
+	}
+
+	err = s.PutObject(ctx, "manifest.json", strings.NewReader("v3"), objex.PutOptions{
+		Conditions: objex.Conditions{IfNoneMatch: "*"},
+	})
+	wantErr(t, "PutObject with If-None-Match: * over an object", err, objex.ErrPreconditionFailed)
+
+	if got := read(t, s, "manifest.json"); got != "v2" {
+		t.Errorf("ReadObject: got %q, want %q", got, "v2")
+	}
+
+	_, _, err = s.OpenObject(ctx, "manifest.json", objex.GetOptions{
+		Conditions: objex.Conditions{IfMatch: etag},
+	})
+	wantErr(t, "OpenObject with a stale If-Match", err, objex.ErrPreconditionFailed)
+
+	if objex.CapabilitiesOf(s).AllConditions {
+		err = s.DeleteObjectIf(ctx, "manifest.json", objex.Conditions{IfUnmodifiedSince: time.Now().Add(-time.Hour)})
+		wantErr(t, "DeleteObjectIf with a past If-Unmodified-Since", err, objex.ErrPreconditionFailed)
+	}
+}
+
+func testMultipart(t *testing.T, s objex.Store, bucket string) {
+	uploader, ok := objex.AsMultipartUploader(s)
+	if !ok {
+		t.Skip("not a MultipartUploader")
+	}
+	ctx := context.Background()
+
+	uploadID, err := uploader.CreateMultipartUpload(ctx, "big.bin", objex.PutOptions{})
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload: %v", err)
+	}
+	defer uploader.AbortMultipartUpload(ctx, "big.bin", uploadID)
+
+	first, err := uploader.UploadPart(ctx, "big.bin", uploadID, 1, strings.NewReader("first part"))
+	if err != nil {
+		t.Fatalf("UploadPart(1): %v", err)
+	}
+	second, err := uploader.UploadPart(ctx, "big.bin", uploadID, 2, strings.NewReader("second part"))
+	if err != nil {
+		t.Fatalf("UploadPart(2): %v", err)
+	}
+
+	err = uploader.CompleteMultipartUpload(ctx, "big.bin", uploadID, []objex.Part{first, first})
+	wantErr(t, "CompleteMultipartUpload with a repeated part", err, objex.ErrInvalidPart)
+
+	err = uploader.CompleteMultipartUpload(ctx, "big.bin", uploadID, []objex.Part{second, first})
+	wantErr(t, "CompleteMultipartUpload with parts out of order", err, objex.ErrInvalidPart)
+
+	// S3 requires every part but the last to be at least 5 MiB, so only the
+	// first part is used.
+	err = uploader.CompleteMultipartUpload(ctx, "big.bin", uploadID, []objex.Part{first})
+	if err != nil {
+		t.Fatalf("CompleteMultipartUpload: %v", err)
+	}
+	if got := read(t, s, "big.bin"); got != "first part" {
+		t.Errorf("ReadObject: got %q, want %q", got, "first part")
+	}
+}
+
+func testVersioning(t *testing.T, s objex.Store, bucket string) {
+	versioner, ok := objex.AsVersioner(s)
+	if !ok {
+		t.Skip("not a Versioner")
+	}
+	ctx := context.Background()
+
+	err := versioner.SetBucketVersioning(ctx, bucket, true)
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("SetBucketVersioning: %v", err)
+	}
+	// Deleting objects only adds delete markers now, so the versions are
+	// removed before newBucket deletes the bucket.
+	t.Cleanup(func() {
+		versions, _ := versioner.ListObjectVersions(ctx, bucket, "")
+		for _, version := range versions {
+			versioner.DeleteObjectVersion(ctx, version.Key, version.VersionID)
+		}
+	})
+
+	status, err := versioner.BucketVersioning(ctx, bucket)
+	if err != nil || status != objex.VersioningEnabled {
+		t.Errorf("BucketVersioning: got %q, %v, want %q", status, err, objex.VersioningEnabled)
+	}
+
+	put(t, s, "doc.txt", "v1", objex.PutOptions{})
+	v1 := metadata(t, s, "doc.txt").VersionID
+	put(t, s, "doc.txt", "v2", objex.PutOptions{})
+	v2 := metadata(t, s, "doc.txt").VersionID
+	if v1 == "" || v2 == "" || v1 == v2 {
+		t.Fatalf("Metadata: got version IDs %q and %q, want two different IDs", v1, v2)
+	}
+
+	versions, err := versioner.ListObjectVersions(ctx, bucket, "")
+	if err != nil {
+		t.Fatalf("ListObjectVersions: %v", err)
+	}
+	if len(versions) != 2 ||
+		versions[0].VersionID != v2 || !versions[0].IsLatest ||
+		versions[1].VersionID != v1 || versions[1].IsLatest {
+		t.Errorf("ListObjectVersions: got %+v, want %s (latest) then %s", versions, v2, v1)
+	}
+
+	body, _, err := versioner.OpenObjectVersion(ctx, "doc.txt", v1, objex.GetOptions{})
+	if err != nil {
+		t.Fatalf("OpenObjectVersion(%s): %v", v1, err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != "v1" {
+		t.Errorf("OpenObjectVersion(%s): got %q, %v, want %q", v1, data, err, "v1")
+	}
+
+	err = s.DeleteObject("doc.txt")
+	if err != nil {
+		t.Fatalf("DeleteObject: %v", err)
+	}
+	_, err = s.ReadObject("doc.txt")
+	wantErr(t, "ReadObject behind a delete marker", err, objex.ErrObjectNotFound)
+
+	versions, err = versioner.ListObjectVersions(ctx, bucket, "")
+	if err != nil {
+		t.Fatalf("ListObjectVersions: %v", err)
+	}
+	if len(versions) != 3 || !versions[0].IsDeleteMarker || !versions[0].IsLatest {
+		t.Fatalf("ListObjectVersions after DeleteObject: got %+v, want a delete marker first", versions)
+	}
+
+	err = versioner.DeleteObjectVersion(ctx, "doc.txt", versions[0].VersionID)
+	if err != nil {
+		t.Fatalf("DeleteObjectVersion of the delete marker: %v", err)
+	}
+	if got := read(t, s, "doc.txt"); got != "v2" {
+		t.Errorf("ReadObject after removing the delete marker: got %q, want %q", got, "v2")
+	}
+
+	err = versioner.DeleteObjectVersion(ctx, "doc.txt", v2)
+	if err != nil {
+		t.Fatalf("DeleteObjectVersion(%s): %v", v2, err)
+	}
+	if got := read(t, s, "doc.txt"); got != "v1" {
+		t.Errorf("ReadObject after deleting the latest version: got %q, want %q", got, "v1")
+	}
+	_, _, err = versioner.OpenObjectVersion(ctx, "doc.txt", v2, objex.GetOptions{})
+	wantErr(t, "OpenObjectVersion of a deleted version", err, objex.ErrVersionNotFound)
+}
+
+func testPresign(t *testing.T, s objex.Store, bucket string) {
+	presigner, ok := objex.AsPresigner(s)
+	if !ok || !objex.CapabilitiesOf(s).Presign {
+		t.Skip("presigning not supported")
+	}
+	ctx := context.Background()
+	put(t, s, "cat.txt", "meow", objex.PutOptions{ContentType: "text/plain"})
+
+	getURL, err := presigner.PresignObject(ctx, http.MethodGet, "cat.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignObject(GET): %v", err)
+	}
+	resp, err := http.Get(getURL)
+	if err != nil {
+		t.Fatalf("GET presigned URL: %v", err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || string(data) != "meow" {
+		t.Errorf("GET presigned URL: got %s %q, %v, want 200 %q", resp.Status, data, err, "meow")
+	}
+
+	putURL, err := presigner.PresignObject(ctx, http.MethodPut, "dog.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignObject(PUT): %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, putURL, strings.NewReader("woof"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT presigned URL: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("PUT presigned URL: got %s, want 200", resp.Status)
+	}
+	if got := read(t, s, "dog.txt"); got != "woof" {
+		t.Errorf("ReadObject after a presigned PUT: got %q, want %q", got, "woof")
+	}
+}
+
+// testCapabilities checks that every capability the store reports is backed
+// by the interface that provides it.
+func testCapabilities(t *testing.T, s objex.Store, bucket string) {
+	caps := objex.CapabilitiesOf(s)
+
+	_, presigner := objex.AsPresigner(s)
+	if caps.Presign && !presigner {
+		t.Error("Capabilities: Presign is reported but the store is not a Presigner")
+	}
+	_, uploader := objex.AsMultipartUploader(s)
+	if caps.Multipart != uploader {
+		t.Errorf("Capabilities: Multipart is %v but AsMultipartUploader reports %v", caps.Multipart, uploader)
+	}
+	versioner, ok := objex.AsVersioner(s)
+	if caps.Versioning != ok {
+		t.Errorf("Capabilities: Versioning is %v but AsVersioner reports %v", caps.Versioning, ok)
+	}
+	if caps.Versioning {
+		_, err := versioner.BucketVersioning(context.Background(), bucket)
+		if err != nil {
+			t.Errorf("BucketVersioning with Versioning reported: %v", err)
+		}
+	}
+	if caps.ObjectLock {
+		t.Error("Capabilities: ObjectLock is reported but objex has no object lock API")
+	}
+}
+
+func testBuckets(t *testing.T, s objex.Store, bucket string) {
+	buckets, err := s.ListBuckets()
+	if err != nil {
+		t.Fatalf("ListBuckets: %v", err)
+	}
+	if !slices.ContainsFunc(buckets, func(b objex.Bucket) bool { return b.Name == bucket }) {
+		t.Errorf("ListBuckets: %s missing from %v", bucket, buckets)
+	}
+
+	put(t, s, "keep.txt", "data", objex.PutOptions{})
+
+	err = s.CreateBucket(bucket)
+	wantErr(t, "CreateBucket of an existing bucket", err, objex.ErrBucketAlreadyExists)
+
+	missing := s.Bucket(bucket + "-missing")
+	err = missing.PutObject(context.Background(), "orphan.txt", strings.NewReader("data"), objex.PutOptions{})
+	wantErr(t, "PutObject into a missing bucket", err, objex.ErrBucketNotFound)
+
+	err = s.DeleteBucket(bucket)
+	wantErr(t, "DeleteBucket of a non-empty bucket", err, objex.ErrBucketNotEmpty)
+
+	if got := read(t, s, "keep.txt"); got != "data" {
+		t.Errorf("ReadObject after a failed DeleteBucket: got %q", got)
+	}
+}
+
+func testCanceledContext(t *testing.T, s objex.Store, bucket string) {
+	put(t, s, "present.txt", "data", objex.PutOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.ReadObjectContext(ctx, "present.txt")
+	wantErr(t, "ReadObjectContext", err, context.Canceled)
+
+	err = s.PutObject(ctx, "never.txt", bytes.NewReader([]byte("data")), objex.PutOptions{})
+	wantErr(t, "PutObject", err, context.Canceled)
+}
+
+func testBucketHandles(t *testing.T, s objex.Store, bucket string) {
+	ctx := context.Background()
+	other := newBucket(t, s)
+
+	handles := []objex.BucketHandle{s.Bucket(bucket), s.Bucket(other)}
+	if handles[0].Name() != bucket {
+		t.Errorf("Name: got %q, want %q", handles[0].Name(), bucket)
+	}
+
+	// Each handle writes its own bucket's name under the same keys.
+	var wg sync.WaitGroup
+	for _, handle := range handles {
+		for _, key := range []string{"a.txt", "b.txt", "c.txt"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := handle.PutObject(ctx, key, strings.NewReader(handle.Name()), objex.PutOptions{})
+				if err != nil {
+					t.Errorf("PutObject(%q) in %s: %v", key, handle.Name(), err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	// Moving the store to another bucket leaves the handles where they were,
+	// including one taken while the store moves.
+	late := make(chan objex.BucketHandle)
+	go func() {
+		late <- s.Bucket(bucket)
+	}()
+	_, err := s.SetBucket(other)
+	if err != nil {
+		t.Fatalf("SetBucket(%q): %v", other, err)
+	}
+	defer s.SetBucket(bucket)
+	handles = append(handles, <-late)
+
+	for _, handle := range handles {
+		data, err := handle.ReadObject(ctx, "b.txt")
+		if err != nil {
+			t.Fatalf("ReadObject in %s: %v", handle.Name(), err)
+		}
+		if string(data) != handle.Name() {
+			t.Errorf("ReadObject in %s: got %q", handle.Name(), data)
+		}
+
+		objects, err := handle.ListObjects(ctx)
+		if err != nil {
+			t.Fatalf("ListObjects in %s: %v", handle.Name(), err)
+		}
+		wantKeys(t, "ListObjects in "+handle.Name(), keys(objects), []string{"a.txt", "b.txt", "c.txt"})
+	}
+
+	err = handles[0].MoveObject(ctx, "a.txt", "moved.txt")
+	if err != nil {
+		t.Fatalf("MoveObject: %v", err)
+	}
+	found, _, err := handles[0].Exists(ctx, "a.txt")
+	if err != nil || found {
+		t.Errorf("Exists after MoveObject: got %v, %v", found, err)
+	}
+	found, _, err = handles[1].Exists(ctx, "a.txt")
+	if err != nil || !found {
+		t.Errorf("Exists in the other bucket after MoveObject: got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,438 @@
+// Package otelobjex instruments an objex.Store with OpenTelemetry traces and
+// metrics.
+package otelobjex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scopeName = "github.com/brian-nunez/objex/otelobjex"
+
+// Attribute keys recorded on spans and metrics. Bucket, key and bytes are
+// only recorded on spans, to keep the number of metric series bounded.
+const (
+	DriverKey    = attribute.Key("objex.driver")
+	OperationKey = attribute.Key("objex.operation")
+	BucketKey    = attribute.Key("objex.bucket")
+	ObjectKey    = attribute.Key("objex.key")
+	BytesKey     = attribute.Key("objex.bytes")
+	OutcomeKey   = attribute.Key("objex.outcome")
+	DirectionKey = attribute.Key("objex.direction")
+	ErrorTypeKey = attribute.Key("error.type")
+)
+
+// Config configures the instrumentation.
+type Config struct {
+	// DriverName is recorded as objex.driver, e.g. "aws" or "filesystem".
+	DriverName string
+	// TracerProvider defaults to otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+	// MeterProvider defaults to otel.GetMeterProvider().
+	MeterProvider metric.MeterProvider
+}
+
+// NewStore returns store instrumented with a span per call and these
+// metrics:
+//
+//   - objex.client.operation.duration, a histogram of call latency in
+//     seconds by driver, operation and outcome
+//   - objex.client.transferred, a counter of bytes uploaded and downloaded
+//     by driver, operation and direction
+//   - objex.client.errors, a counter of failed calls by driver, operation
+//     and error.type, the objex.Err* kind such as OBJECT_NOT_FOUND
+//
+// Bytes read through OpenObject are counted when the body is closed. The
+// bucket recorded for object names starts as the current bucket of store,
+// as reported by objex.CurrentBucket, and follows SetBucket calls made
+// through the returned Store.
+func NewStore(store objex.Store, config Config) objex.Store {
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.MeterProvider == nil {
+		config.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := config.MeterProvider.Meter(scopeName)
+	s := &instrumented{
+		Passthrough: objex.Passthrough{StoreContext: store},
+		driver:      config.DriverName,
+		tracer:      config.TracerProvider.Tracer(scopeName),
+		bucket:      objex.CurrentBucket(store),
+	}
+
+	var err error
+	s.duration, err = meter.Float64Histogram("objex.client.operation.duration",
+		metric.WithDescription("Duration of objex store calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	s.transferred, err = meter.Int64Counter("objex.client.transferred",
+		metric.WithDescription("Bytes uploaded to and downloaded from the store."),
+		metric.WithUnit("By"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	s.errors, err = meter.Int64Counter("objex.client.errors",
+		metric.WithDescription("Failed objex store calls."),
+		metric.WithUnit("{error}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return objex.Adapt(s)
+}
+
+// Middleware is NewStore as an objex.Middleware.
+func Middleware(config Config) objex.Middleware {
+	return func(store objex.Store) objex.Store {
+		return NewStore(store, config)
+	}
+}
+
+type instrumented struct {
+	objex.Passthrough
+	driver string
+	tracer trace.Tracer
+
+	duration    metric.Float64Histogram
+	transferred metric.Int64Counter
+	errors      metric.Int64Counter
+
+	mu     sync.Mutex
+	bucket string
+}
+
+// call is one instrumented store call.
+type call struct {
+	s     *instrumented
+	ctx   context.Context
+	span  trace.Span
+	op    string
+	start time.Time
+}
+
+func (s *instrumented) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, *call) {
+	attrs = append(attrs, DriverKey.String(s.driver), OperationKey.String(op))
+	ctx, span := s.tracer.Start(ctx, "objex."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, &call{s: s, ctx: ctx, span: span, op: op, start: time.Now()}
+}
+
+func (s *instrumented) currentBucket() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bucket
+}
+
+// startBucket starts a call on bucketName, or the current bucket when it is
+// empty.
+func (s *instrumented) startBucket(ctx context.Context, op, bucketName string) (context.Context, *call) {
+	if bucketName == "" {
+		bucketName = s.currentBucket()
+	}
+	return s.start(ctx, op, BucketKey.String(bucketName))
+}
+
+// startObject starts a call on the object name, recording the bucket and
+// key it resolves to.
+func (s *instrumented) startObject(ctx context.Context, op, name string) (context.Context, *call) {
+	bucket, key, err := objex.SplitPath(s.currentBucket(), name)
+	if err != nil {
+		return s.start(ctx, op, ObjectKey.String(name))
+	}
+	return s.start(ctx, op, BucketKey.String(bucket), ObjectKey.String(key))
+}
+
+func (c *call) metricAttrs(attrs ...attribute.KeyValue) metric.MeasurementOption {
+	attrs = append(attrs, DriverKey.String(c.s.driver), OperationKey.String(c.op))
+	return metric.WithAttributes(attrs...)
+}
+
+// transfer records n bytes moved in direction, "upload" or "download".
+func (c *call) transfer(n int64, direction string) {
+	if n == 0 {
+		return
+	}
+	c.span.SetAttributes(BytesKey.Int64(n))
+	c.s.transferred.Add(c.ctx, n, c.metricAttrs(DirectionKey.String(direction)))
+}
+
+// end ends the call and records its outcome.
+func (c *call) end(err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+
+		errorType := errorType(err)
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, errorType)
+		c.span.SetAttributes(ErrorTypeKey.String(errorType))
+		c.s.errors.Add(c.ctx, 1, c.metricAttrs(ErrorTypeKey.String(errorType)))
+	}
+
+	c.s.duration.Record(c.ctx, time.Since(c.start).Seconds(), c.metricAttrs(OutcomeKey.String(outcome)))
+	c.span.End()
+}
+
+func errorType(err error) string {
+	if kind := objex.KindOf(err); kind != nil {
+		return kind.Error()
+	}
+	if errors.Is(err, context.Canceled) {
+		return "CANCELED"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "DEADLINE_EXCEEDED"
+	}
+	return "OTHER"
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingBody counts the bytes read from an OpenObject body and records
+// them when it is closed.
+type countingBody struct {
+	countingReader
+	closer io.Closer
+	call   *call
+	once   sync.Once
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() {
+		if b.n == 0 {
+			return
+		}
+		b.call.s.transferred.Add(context.WithoutCancel(b.call.ctx), b.n,
+			b.call.metricAttrs(DirectionKey.String("download")))
+	})
+	return b.closer.Close()
+}
+
+// Bucket returns a handle whose calls are instrumented too.
+func (s *instrumented) Bucket(bucketName string) objex.BucketHandle {
+	return objex.WrapBucket(s.StoreContext.Bucket(bucketName), func(store objex.Store) objex.Store {
+		return objex.Adapt(&instrumented{
+			Passthrough: objex.Passthrough{StoreContext: store},
+			driver:      s.driver,
+			tracer:      s.tracer,
+			duration:    s.duration,
+			transferred: s.transferred,
+			errors:      s.errors,
+			bucket:      bucketName,
+		})
+	})
+}
+
+func (s *instrumented) SetupContext(ctx context.Context) error {
+	ctx, c := s.start(ctx, "Setup")
+	err := s.StoreContext.SetupContext(ctx)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) SetBucketContext(ctx context.Context, bucketName string) (bool, error) {
+	ctx, c := s.start(ctx, "SetBucket", BucketKey.String(bucketName))
+	found, err := s.StoreContext.SetBucketContext(ctx, bucketName)
+	c.end(err)
+
+	if err == nil {
+		s.mu.Lock()
+		s.bucket = bucketName
+		s.mu.Unlock()
+	}
+	return found, err
+}
+
+func (s *instrumented) SetRegionContext(ctx context.Context, region string) error {
+	ctx, c := s.start(ctx, "SetRegion")
+	err := s.StoreContext.SetRegionContext(ctx, region)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) CreateBucketContext(ctx context.Context, bucketName string) error {
+	ctx, c := s.start(ctx, "CreateBucket", BucketKey.String(bucketName))
+	err := s.StoreContext.CreateBucketContext(ctx, bucketName)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) DeleteBucketContext(ctx context.Context, bucketName string) error {
+	ctx, c := s.start(ctx, "DeleteBucket", BucketKey.String(bucketName))
+	err := s.StoreContext.DeleteBucketContext(ctx, bucketName)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) ListBucketsContext(ctx context.Context) ([]objex.Bucket, error) {
+	ctx, c := s.start(ctx, "ListBuckets")
+	buckets, err := s.StoreContext.ListBucketsContext(ctx)
+	c.end(err)
+	return buckets, err
+}
+
+func (s *instrumented) CreateObjectContext(ctx context.Context, objectName string, data io.Reader, contentType string) error {
+	ctx, c := s.startObject(ctx, "CreateObject", objectName)
+	counted := &countingReader{Reader: data}
+	err := s.StoreContext.CreateObjectContext(ctx, objectName, counted, contentType)
+	c.transfer(counted.n, "upload")
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) PutObject(ctx context.Context, objectName string, data io.Reader, opts objex.PutOptions) error {
+	ctx, c := s.startObject(ctx, "PutObject", objectName)
+	counted := &countingReader{Reader: data}
+	err := s.StoreContext.PutObject(ctx, objectName, counted, opts)
+	c.transfer(counted.n, "upload")
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) ReadObjectContext(ctx context.Context, fileName string) ([]byte, error) {
+	ctx, c := s.startObject(ctx, "ReadObject", fileName)
+	data, err := s.StoreContext.ReadObjectContext(ctx, fileName)
+	c.transfer(int64(len(data)), "download")
+	c.end(err)
+	return data, err
+}
+
+func (s *instrumented) OpenObject(ctx context.Context, fileName string, opts objex.GetOptions) (io.ReadCloser, *objex.ObjectMetaData, error) {
+	ctx, c := s.startObject(ctx, "OpenObject", fileName)
+	body, meta, err := s.StoreContext.OpenObject(ctx, fileName, opts)
+	c.end(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &countingBody{countingReader: countingReader{Reader: body}, closer: body, call: c}, meta, nil
+}
+
+func (s *instrumented) UpdateObjectContext(ctx context.Context, fileName string, data io.Reader) error {
+	ctx, c := s.startObject(ctx, "UpdateObject", fileName)
+	counted := &countingReader{Reader: data}
+	err := s.StoreContext.UpdateObjectContext(ctx, fileName, counted)
+	c.transfer(counted.n, "upload")
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) DeleteObjectContext(ctx context.Context, fileName string) error {
+	ctx, c := s.startObject(ctx, "DeleteObject", fileName)
+	err := s.StoreContext.DeleteObjectContext(ctx, fileName)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) DeleteObjectIf(ctx context.Context, fileName string, cond objex.Conditions) error {
+	ctx, c := s.startObject(ctx, "DeleteObjectIf", fileName)
+	err := s.StoreContext.DeleteObjectIf(ctx, fileName, cond)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) ListObjectsContext(ctx context.Context, bucketName string) ([]*objex.ObjectMetaData, error) {
+	ctx, c := s.startBucket(ctx, "ListObjects", bucketName)
+	objects, err := s.StoreContext.ListObjectsContext(ctx, bucketName)
+	c.end(err)
+	return objects, err
+}
+
+func (s *instrumented) ListObjectsPage(ctx context.Context, bucketName string, opts objex.ListOptions) (*objex.ListResult, error) {
+	ctx, c := s.startBucket(ctx, "ListObjectsPage", bucketName)
+	page, err := s.StoreContext.ListObjectsPage(ctx, bucketName, opts)
+	c.end(err)
+	return page, err
+}
+
+// Objects records one span for the whole iteration, ending when the loop
+// does.
+func (s *instrumented) Objects(ctx context.Context, bucketName string, opts objex.ListOptions) iter.Seq2[*objex.ObjectMetaData, error] {
+	return func(yield func(*objex.ObjectMetaData, error) bool) {
+		ctx, c := s.startBucket(ctx, "Objects", bucketName)
+
+		var err error
+		for object, objectErr := range s.StoreContext.Objects(ctx, bucketName, opts) {
+			err = objectErr
+			if !yield(object, objectErr) {
+				break
+			}
+		}
+		c.end(err)
+	}
+}
+
+func (s *instrumented) ExistsContext(ctx context.Context, fileName string) (bool, *objex.ObjectMetaData, error) {
+	ctx, c := s.startObject(ctx, "Exists", fileName)
+	found, meta, err := s.StoreContext.ExistsContext(ctx, fileName)
+	c.end(err)
+	return found, meta, err
+}
+
+func (s *instrumented) MetadataContext(ctx context.Context, fileName string) (*objex.ObjectMetaData, error) {
+	ctx, c := s.startObject(ctx, "Metadata", fileName)
+	meta, err := s.StoreContext.MetadataContext(ctx, fileName)
+	c.end(err)
+	return meta, err
+}
+
+func (s *instrumented) CopyObjectContext(ctx context.Context, fileSource, fileDestination string) error {
+	ctx, c := s.startObject(ctx, "CopyObject", fileSource)
+	err := s.StoreContext.CopyObjectContext(ctx, fileSource, fileDestination)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) CopyObjectIf(ctx context.Context, fileSource, fileDestination string, cond objex.CopyConditions) error {
+	ctx, c := s.startObject(ctx, "CopyObjectIf", fileSource)
+	err := s.StoreContext.CopyObjectIf(ctx, fileSource, fileDestination, cond)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) MoveObjectContext(ctx context.Context, fileSource, fileDestination string) error {
+	ctx, c := s.startObject(ctx, "MoveObject", fileSource)
+	err := s.StoreContext.MoveObjectContext(ctx, fileSource, fileDestination)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) CleanUpContext(ctx context.Context) error {
+	ctx, c := s.start(ctx, "CleanUp")
+	err := s.StoreContext.CleanUpContext(ctx)
+	c.end(err)
+	return err
+}
+
+func (s *instrumented) HealthCheckContext(ctx context.Context) error {
+	ctx, c := s.start(ctx, "HealthCheck")
+	err := s.StoreContext.HealthCheckContext(ctx)
+	c.end(err)
+	return err
+}
@@ -0,0 +1,174 @@
+package otelobjex_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+	"github.com/brian-nunez/objex/otelobjex"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewStore(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	inner, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := otelobjex.NewStore(inner, otelobjex.Config{
+		DriverName:     "memory",
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+
+	ctx := context.Background()
+	_, err = store.SetBucket("photos")
+	if err != nil {
+		t.Fatalf("SetBucket: %v", err)
+	}
+	err = store.PutObject(ctx, "cat.jpg", strings.NewReader("meow"), objex.PutOptions{})
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	_, err = store.ReadObjectContext(ctx, "missing.jpg")
+	if !errors.Is(err, objex.ErrObjectNotFound) {
+		t.Fatalf("ReadObject: got %v, want %v", err, objex.ErrObjectNotFound)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want 3", len(ended))
+	}
+
+	put := ended[1]
+	if put.Name() != "objex.PutObject" {
+		t.Errorf("span name: got %q, want %q", put.Name(), "objex.PutObject")
+	}
+	wantAttrs(t, put.Name(), put.Attributes(),
+		otelobjex.DriverKey.String("memory"),
+		otelobjex.OperationKey.String("PutObject"),
+		otelobjex.BucketKey.String("photos"),
+		otelobjex.ObjectKey.String("cat.jpg"),
+		otelobjex.BytesKey.Int64(4),
+	)
+	if put.Status().Code != codes.Unset {
+		t.Errorf("%s status: got %v, want unset", put.Name(), put.Status().Code)
+	}
+
+	read := ended[2]
+	if read.Name() != "objex.ReadObject" {
+		t.Errorf("span name: got %q, want %q", read.Name(), "objex.ReadObject")
+	}
+	wantAttrs(t, read.Name(), read.Attributes(),
+		otelobjex.OperationKey.String("ReadObject"),
+		otelobjex.ObjectKey.String("missing.jpg"),
+		otelobjex.ErrorTypeKey.String("OBJECT_NOT_FOUND"),
+	)
+	if read.Status().Code != codes.Error || read.Status().Description != "OBJECT_NOT_FOUND" {
+		t.Errorf("%s status: got %+v, want an OBJECT_NOT_FOUND error", read.Name(), read.Status())
+	}
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(ctx, &rm)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	durations := metricNamed[metricdata.Histogram[float64]](t, rm, "objex.client.operation.duration")
+	counts := make(map[string]uint64)
+	for _, point := range durations.DataPoints {
+		op, _ := point.Attributes.Value(otelobjex.OperationKey)
+		outcome, _ := point.Attributes.Value(otelobjex.OutcomeKey)
+		counts[op.AsString()+" "+outcome.AsString()] += point.Count
+	}
+	if counts["PutObject ok"] != 1 || counts["ReadObject error"] != 1 || len(counts) != 3 {
+		t.Errorf("duration counts: got %v, want one ok PutObject and one failed ReadObject", counts)
+	}
+
+	failures := metricNamed[metricdata.Sum[int64]](t, rm, "objex.client.errors")
+	if len(failures.DataPoints) != 1 {
+		t.Fatalf("errors: got %d data points, want 1", len(failures.DataPoints))
+	}
+	point := failures.DataPoints[0]
+	if point.Value != 1 {
+		t.Errorf("errors: got %d, want 1", point.Value)
+	}
+	if kind, _ := point.Attributes.Value(otelobjex.ErrorTypeKey); kind.AsString() != "OBJECT_NOT_FOUND" {
+		t.Errorf("errors: got error.type %q, want OBJECT_NOT_FOUND", kind.AsString())
+	}
+	if op, _ := point.Attributes.Value(otelobjex.OperationKey); op.AsString() != "ReadObject" {
+		t.Errorf("errors: got operation %q, want ReadObject", op.AsString())
+	}
+}
+
+// Object names resolve against the bucket the wrapped store was configured
+// with, before any SetBucket call.
+func TestNewStoreConfiguredBucket(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+
+	inner, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := otelobjex.NewStore(inner, otelobjex.Config{
+		DriverName:     "memory",
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(),
+	})
+
+	err = store.PutObject(context.Background(), "cat.jpg", strings.NewReader("meow"), objex.PutOptions{})
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+	wantAttrs(t, ended[0].Name(), ended[0].Attributes(),
+		otelobjex.BucketKey.String("photos"),
+		otelobjex.ObjectKey.String("cat.jpg"),
+	)
+}
+
+func wantAttrs(t *testing.T, span string, got []attribute.KeyValue, want ...attribute.KeyValue) {
+	t.Helper()
+
+	set := attribute.NewSet(got...)
+	for _, attr := range want {
+		value, ok := set.Value(attr.Key)
+		if !ok || value != attr.Value {
+			t.Errorf("%s: got %s=%v, want %v", span, attr.Key, value.Emit(), attr.Value.Emit())
+		}
+	}
+}
+
+func metricNamed[T metricdata.Aggregation](t *testing.T, rm metricdata.ResourceMetrics, name string) T {
+	t.Helper()
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			data, ok := m.Data.(T)
+			if !ok {
+				t.Fatalf("%s: got %T", name, m.Data)
+			}
+			return data
+		}
+	}
+	t.Fatalf("no %s metric", name)
+	var zero T
+	return zero
+}
@@ -0,0 +1,21 @@
+package objex
+
+import (
+	"context"
+	"time"
+)
+
+// Presigner is implemented by stores that can hand out time-limited URLs
+// that let a client access an object directly, without credentials.
+type Presigner interface {
+	// PresignObject returns a URL valid for expires that performs method
+	// (http.MethodGet, MethodPut, MethodHead or MethodDelete) on the object.
+	// Other methods fail with ErrNotSupported.
+	PresignObject(ctx context.Context, method, objectName string, expires time.Duration) (string, error)
+}
+
+// AsPresigner returns the Presigner behind store, looking through wrappers
+// such as the one returned by Adapt.
+func AsPresigner(store StoreContext) (Presigner, bool) {
+	return as[Presigner](store)
+}
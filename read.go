@@ -0,0 +1,103 @@
+package objex
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+)
+
+// GetOptions controls how OpenObject reads an object.
+type GetOptions struct {
+	// Range limits the read to part of the object. Nil reads everything.
+	Range *Range
+	// Conditions must hold for the object, or OpenObject fails with
+	// ErrPreconditionFailed.
+	Conditions Conditions
+}
+
+// Range selects the bytes of an object to read, mirroring an HTTP Range
+// header. The zero value selects the whole object.
+type Range struct {
+	// Offset is the first byte to read.
+	Offset int64
+	// Length is the number of bytes to read. Zero reads to the end.
+	Length int64
+	// Suffix, when positive, selects the last Suffix bytes of the object
+	// and Offset and Length are ignored.
+	Suffix int64
+}
+
+// Validate reports ErrInvalidRange for negative fields.
+func (r Range) Validate() error {
+	if r.Offset < 0 || r.Length < 0 || r.Suffix < 0 {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// Header formats the range as an HTTP Range header value.
+func (r Range) Header() string {
+	if r.Suffix > 0 {
+		return "bytes=-" + strconv.FormatInt(r.Suffix, 10)
+	}
+
+	header := "bytes=" + strconv.FormatInt(r.Offset, 10) + "-"
+	if r.Length > 0 {
+		header += strconv.FormatInt(r.Offset+r.Length-1, 10)
+	}
+	return header
+}
+
+// Bounds resolves the range against an object of the given size and returns
+// the offset and number of bytes to read. Like S3, a range that starts past
+// the end of the object is unsatisfiable and one that runs past the end is
+// truncated. The zero Range is the whole object, even an empty one.
+func (r Range) Bounds(size int64) (offset, length int64, err error) {
+	if err := r.Validate(); err != nil {
+		return 0, 0, err
+	}
+	if r == (Range{}) {
+		return 0, size, nil
+	}
+
+	if r.Suffix > 0 {
+		if size == 0 {
+			return 0, 0, ErrInvalidRange
+		}
+		length = min(r.Suffix, size)
+		return size - length, length, nil
+	}
+
+	if r.Offset >= size {
+		return 0, 0, ErrInvalidRange
+	}
+
+	length = size - r.Offset
+	if r.Length > 0 {
+		length = min(r.Length, length)
+	}
+	return r.Offset, length, nil
+}
+
+// ReadObject reads a whole object into memory through store.OpenObject.
+// Drivers use it to implement ReadObjectContext; prefer OpenObject for large
+// objects.
+func ReadObject(ctx context.Context, store StoreContext, fileName string) ([]byte, error) {
+	body, meta, err := store.OpenObject(ctx, fileName, GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	if meta != nil && meta.Size > 0 {
+		buf.Grow(int(meta.Size))
+	}
+
+	_, err = buf.ReadFrom(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
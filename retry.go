@@ -0,0 +1,344 @@
+package objex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryOptions configures the Store returned by NewRetryStore. Zero fields
+// take the defaults noted on each field.
+type RetryOptions struct {
+	// MaxAttempts is the number of times a call is tried, including the
+	// first. Defaults to 3.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry. It doubles with every
+	// further attempt. Defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts. Defaults to 5s.
+	MaxDelay time.Duration
+	// Jitter is the fraction, from 0 to 1, of each wait that is randomized,
+	// so clients that failed together do not retry together. Zero waits
+	// exactly the backoff delay.
+	Jitter float64
+	// Retryable reports whether a failed call may be tried again. Defaults
+	// to IsRetryable.
+	Retryable func(err error) bool
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = 100 * time.Millisecond
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 5 * time.Second
+	}
+	o.Jitter = min(max(o.Jitter, 0), 1)
+	if o.Retryable == nil {
+		o.Retryable = IsRetryable
+	}
+	return o
+}
+
+// delay returns the wait after the given failed attempt, counting from 1.
+func (o RetryOptions) delay(attempt int) time.Duration {
+	delay := o.MaxDelay
+	if attempt < 32 {
+		delay = min(o.BaseDelay<<(attempt-1), o.MaxDelay)
+	}
+	if delay <= 0 {
+		delay = o.MaxDelay
+	}
+	if o.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * o.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// IsRetryable reports whether err is transient: ErrThrottled,
+// ErrUnavailable, a network timeout or a dropped connection. Canceled and
+// expired contexts are never retried.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, ErrThrottled) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// NewRetryStore returns a Store that retries the calls of store that fail
+// with a retryable error, waiting with exponential backoff between attempts.
+//
+// Uploads are only retried when their data is an io.ReadSeeker, which is
+// seeked back to where it started before every attempt; other readers
+// cannot be replayed and get a single attempt. Objects resumes after the
+// last object it yielded. Optional interfaces such as Presigner are found on
+// store and are not retried.
+func NewRetryStore(store Store, opts RetryOptions) Store {
+	return Adapt(&retryStore{Passthrough: Passthrough{StoreContext: store}, opts: opts.withDefaults()})
+}
+
+// Retry is NewRetryStore as a Middleware.
+func Retry(opts RetryOptions) Middleware {
+	return func(store Store) Store {
+		return NewRetryStore(store, opts)
+	}
+}
+
+type retryStore struct {
+	Passthrough
+	opts RetryOptions
+}
+
+// Bucket returns a handle whose calls are retried too.
+func (r *retryStore) Bucket(bucketName string) BucketHandle {
+	return WrapBucket(r.StoreContext.Bucket(bucketName), Retry(r.opts))
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable, runs out of attempts or ctx is done.
+func (r *retryStore) retry(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !r.backoff(ctx, attempt, err) {
+			return err
+		}
+	}
+}
+
+// backoff reports whether the call that failed with err on the given
+// attempt should be tried again, waiting out the delay before returning.
+func (r *retryStore) backoff(ctx context.Context, attempt int, err error) bool {
+	if attempt >= r.opts.MaxAttempts || !r.opts.Retryable(err) {
+		return false
+	}
+
+	timer := time.NewTimer(r.opts.delay(attempt))
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryBody is retry for calls that upload data. An io.ReadSeeker is seeked
+// back to its starting offset before every attempt after the first.
+func (r *retryStore) retryBody(ctx context.Context, data io.Reader, fn func(data io.Reader) error) error {
+	seeker, ok := data.(io.ReadSeeker)
+	if !ok {
+		return fn(data)
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fn(data)
+	}
+
+	first := true
+	return r.retry(ctx, func() error {
+		if !first {
+			_, err := seeker.Seek(start, io.SeekStart)
+			if err != nil {
+				return err
+			}
+		}
+		first = false
+		return fn(seeker)
+	})
+}
+
+func (r *retryStore) SetupContext(ctx context.Context) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.SetupContext(ctx)
+	})
+}
+
+func (r *retryStore) SetBucketContext(ctx context.Context, bucketName string) (found bool, err error) {
+	err = r.retry(ctx, func() (err error) {
+		found, err = r.StoreContext.SetBucketContext(ctx, bucketName)
+		return err
+	})
+	return found, err
+}
+
+func (r *retryStore) CreateBucketContext(ctx context.Context, bucketName string) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.CreateBucketContext(ctx, bucketName)
+	})
+}
+
+func (r *retryStore) DeleteBucketContext(ctx context.Context, bucketName string) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.DeleteBucketContext(ctx, bucketName)
+	})
+}
+
+func (r *retryStore) ListBucketsContext(ctx context.Context) (buckets []Bucket, err error) {
+	err = r.retry(ctx, func() (err error) {
+		buckets, err = r.StoreContext.ListBucketsContext(ctx)
+		return err
+	})
+	return buckets, err
+}
+
+func (r *retryStore) CreateObjectContext(ctx context.Context, objectName string, data io.Reader, contentType string) error {
+	return r.retryBody(ctx, data, func(data io.Reader) error {
+		return r.StoreContext.CreateObjectContext(ctx, objectName, data, contentType)
+	})
+}
+
+func (r *retryStore) PutObject(ctx context.Context, objectName string, data io.Reader, opts PutOptions) error {
+	return r.retryBody(ctx, data, func(data io.Reader) error {
+		return r.StoreContext.PutObject(ctx, objectName, data, opts)
+	})
+}
+
+func (r *retryStore) ReadObjectContext(ctx context.Context, fileName string) (data []byte, err error) {
+	err = r.retry(ctx, func() (err error) {
+		data, err = r.StoreContext.ReadObjectContext(ctx, fileName)
+		return err
+	})
+	return data, err
+}
+
+// OpenObject retries opening the object. Reading the returned body is not
+// retried.
+func (r *retryStore) OpenObject(ctx context.Context, fileName string, opts GetOptions) (body io.ReadCloser, meta *ObjectMetaData, err error) {
+	err = r.retry(ctx, func() (err error) {
+		body, meta, err = r.StoreContext.OpenObject(ctx, fileName, opts)
+		return err
+	})
+	return body, meta, err
+}
+
+func (r *retryStore) UpdateObjectContext(ctx context.Context, fileName string, data io.Reader) error {
+	return r.retryBody(ctx, data, func(data io.Reader) error {
+		return r.StoreContext.UpdateObjectContext(ctx, fileName, data)
+	})
+}
+
+func (r *retryStore) DeleteObjectContext(ctx context.Context, fileName string) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.DeleteObjectContext(ctx, fileName)
+	})
+}
+
+func (r *retryStore) DeleteObjectIf(ctx context.Context, fileName string, cond Conditions) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.DeleteObjectIf(ctx, fileName, cond)
+	})
+}
+
+func (r *retryStore) ListObjectsContext(ctx context.Context, bucketName string) (objects []*ObjectMetaData, err error) {
+	err = r.retry(ctx, func() (err error) {
+		objects, err = r.StoreContext.ListObjectsContext(ctx, bucketName)
+		return err
+	})
+	return objects, err
+}
+
+func (r *retryStore) ListObjectsPage(ctx context.Context, bucketName string, opts ListOptions) (page *ListResult, err error) {
+	err = r.retry(ctx, func() (err error) {
+		page, err = r.StoreContext.ListObjectsPage(ctx, bucketName, opts)
+		return err
+	})
+	return page, err
+}
+
+// Objects restarts the listing after the last object it yielded when the
+// underlying iteration fails with a retryable error. Every object yielded
+// restores the full attempt budget.
+func (r *retryStore) Objects(ctx context.Context, bucketName string, opts ListOptions) iter.Seq2[*ObjectMetaData, error] {
+	return func(yield func(*ObjectMetaData, error) bool) {
+		cursor := opts
+		for attempt := 1; ; attempt++ {
+			var err error
+			for object, iterErr := range r.StoreContext.Objects(ctx, bucketName, cursor) {
+				if iterErr != nil {
+					err = iterErr
+					break
+				}
+				if !yield(object, nil) {
+					return
+				}
+				cursor.StartAfter = object.Key
+				cursor.ContinuationToken = ""
+				attempt = 1
+			}
+			if err == nil {
+				return
+			}
+			if !r.backoff(ctx, attempt, err) {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+func (r *retryStore) ExistsContext(ctx context.Context, fileName string) (found bool, meta *ObjectMetaData, err error) {
+	err = r.retry(ctx, func() (err error) {
+		found, meta, err = r.StoreContext.ExistsContext(ctx, fileName)
+		return err
+	})
+	return found, meta, err
+}
+
+func (r *retryStore) MetadataContext(ctx context.Context, fileName string) (meta *ObjectMetaData, err error) {
+	err = r.retry(ctx, func() (err error) {
+		meta, err = r.StoreContext.MetadataContext(ctx, fileName)
+		return err
+	})
+	return meta, err
+}
+
+func (r *retryStore) CopyObjectContext(ctx context.Context, fileSource, fileDestination string) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.CopyObjectContext(ctx, fileSource, fileDestination)
+	})
+}
+
+func (r *retryStore) CopyObjectIf(ctx context.Context, fileSource, fileDestination string, cond CopyConditions) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.CopyObjectIf(ctx, fileSource, fileDestination, cond)
+	})
+}
+
+// MoveObjectContext retries the driver's own move, which may be a single
+// atomic rename.
+func (r *retryStore) MoveObjectContext(ctx context.Context, fileSource, fileDestination string) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.MoveObjectContext(ctx, fileSource, fileDestination)
+	})
+}
+
+func (r *retryStore) CleanUpContext(ctx context.Context) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.CleanUpContext(ctx)
+	})
+}
+
+func (r *retryStore) HealthCheckContext(ctx context.Context) error {
+	return r.retry(ctx, func() error {
+		return r.StoreContext.HealthCheckContext(ctx)
+	})
+}
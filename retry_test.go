@@ -0,0 +1,175 @@
+package objex_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+)
+
+var throttled = &objex.Error{Kind: objex.ErrThrottled, Op: "PutObject", Code: "SlowDown", StatusCode: 503}
+
+// flakyStore fails the next calls with errs, one each, before passing
+// calls on to the memory store it wraps. It records every call.
+type flakyStore struct {
+	objex.Passthrough
+	errs   []error
+	calls  int
+	bodies []string
+}
+
+func newFlakyStore(t *testing.T, errs ...error) *flakyStore {
+	t.Helper()
+
+	mem, err := memory.NewStore(memory.Config{Bucket: "photos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &flakyStore{Passthrough: objex.Passthrough{StoreContext: mem}, errs: errs}
+}
+
+func (f *flakyStore) fail() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *flakyStore) PutObject(ctx context.Context, objectName string, data io.Reader, opts objex.PutOptions) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	f.bodies = append(f.bodies, string(body))
+
+	err = f.fail()
+	if err != nil {
+		return err
+	}
+	return f.StoreContext.PutObject(ctx, objectName, bytes.NewReader(body), opts)
+}
+
+func (f *flakyStore) ReadObjectContext(ctx context.Context, fileName string) ([]byte, error) {
+	err := f.fail()
+	if err != nil {
+		return nil, err
+	}
+	return f.StoreContext.ReadObjectContext(ctx, fileName)
+}
+
+func TestRetryAttempts(t *testing.T) {
+	opts := objex.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	flaky := newFlakyStore(t, throttled, objex.ErrUnavailable)
+	store := objex.NewRetryStore(objex.Adapt(flaky), opts)
+	err := store.PutObject(context.Background(), "cat.jpg", strings.NewReader("meow"), objex.PutOptions{})
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("PutObject: got %d attempts, want 3", flaky.calls)
+	}
+	// The body is seeked back and sent in full every time.
+	for i, body := range flaky.bodies {
+		if body != "meow" {
+			t.Errorf("PutObject attempt %d: got body %q, want %q", i+1, body, "meow")
+		}
+	}
+
+	flaky = newFlakyStore(t, throttled, throttled, throttled, throttled)
+	store = objex.NewRetryStore(objex.Adapt(flaky), opts)
+	err = store.PutObject(context.Background(), "cat.jpg", strings.NewReader("meow"), objex.PutOptions{})
+	wantErr(t, "PutObject", err, objex.ErrThrottled)
+	if flaky.calls != 3 {
+		t.Errorf("PutObject: got %d attempts, want 3", flaky.calls)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(store objex.Store) error
+		want error
+	}{
+		{"NotFound", func(store objex.Store) error {
+			_, err := store.ReadObject("missing.jpg")
+			return err
+		}, objex.ErrObjectNotFound},
+		{"PreconditionFailed", func(store objex.Store) error {
+			return store.PutObject(context.Background(), "cat.jpg", strings.NewReader("meow"), objex.PutOptions{
+				Conditions: objex.Conditions{IfMatch: `"not-the-etag"`},
+			})
+		}, objex.ErrPreconditionFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flaky := newFlakyStore(t)
+			store := objex.NewRetryStore(objex.Adapt(flaky), objex.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+			wantErr(t, test.name, test.call(store), test.want)
+			if flaky.calls != 1 {
+				t.Errorf("got %d attempts, want 1", flaky.calls)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Run("Capped", func(t *testing.T) {
+		flaky := newFlakyStore(t, throttled, throttled)
+		store := objex.NewRetryStore(objex.Adapt(flaky), objex.RetryOptions{
+			MaxAttempts: 3,
+			BaseDelay:   time.Hour,
+			MaxDelay:    time.Millisecond,
+		})
+
+		start := time.Now()
+		_, err := store.ReadObject("missing.jpg")
+		wantErr(t, "ReadObject", err, objex.ErrObjectNotFound)
+		if flaky.calls != 3 {
+			t.Errorf("ReadObject: got %d attempts, want 3", flaky.calls)
+		}
+		if elapsed := time.Since(start); elapsed > time.Minute {
+			t.Errorf("ReadObject: took %v, want MaxDelay to cap the hour long backoff", elapsed)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		flaky := newFlakyStore(t, throttled, throttled)
+		store := objex.NewRetryStore(objex.Adapt(flaky), objex.RetryOptions{
+			MaxAttempts: 3,
+			BaseDelay:   time.Hour,
+			MaxDelay:    time.Hour,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := store.ReadObjectContext(ctx, "missing.jpg")
+		wantErr(t, "ReadObject", err, objex.ErrThrottled)
+		if flaky.calls != 1 {
+			t.Errorf("ReadObject: got %d attempts, want 1", flaky.calls)
+		}
+	})
+}
+
+func TestRetryNonSeekableBody(t *testing.T) {
+	flaky := newFlakyStore(t, throttled)
+	store := objex.NewRetryStore(objex.Adapt(flaky), objex.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	// io.MultiReader hides the Seek method of the strings.Reader.
+	body := io.MultiReader(strings.NewReader("meow"))
+	err := store.PutObject(context.Background(), "cat.jpg", body, objex.PutOptions{})
+	wantErr(t, "PutObject", err, objex.ErrThrottled)
+	if flaky.calls != 1 {
+		t.Errorf("PutObject: got %d attempts, want 1", flaky.calls)
+	}
+}
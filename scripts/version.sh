@@ -7,9 +7,13 @@ git tag $TAG
 git tag drivers/aws/$TAG
 git tag drivers/minio/$TAG
 git tag drivers/filesystem/$TAG
+git tag drivers/memory/$TAG
+git tag otelobjex/$TAG
 
 # Push the correct tags
 git push origin $TAG
 git push origin drivers/aws/$TAG
 git push origin drivers/minio/$TAG
 git push origin drivers/filesystem/$TAG
+git push origin drivers/memory/$TAG
+git push origin otelobjex/$TAG
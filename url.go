@@ -0,0 +1,54 @@
+package objex
+
+import (
+	"errors"
+	"net/url"
+)
+
+// URLParser turns a store URL into the Config of its driver.
+type URLParser func(u *url.URL) (NamedConfig, error)
+
+var urlParsers = make(map[string]URLParser)
+
+// RegisterURL registers the parser for store URLs with the given scheme.
+// Drivers call it from init next to Register.
+func RegisterURL(scheme string, parser URLParser) {
+	urlParsers[scheme] = parser
+}
+
+// ParseURL returns the driver Config described by a store URL, such as
+// "s3://key:secret@host:9000/bucket?region=us-east-1", "file:///var/data/bucket"
+// or "mem://". The driver for the scheme must be imported. Unknown schemes
+// fail with ErrUnknownDriver and malformed URLs with ErrInvalidEndpoint.
+func ParseURL(rawURL string) (NamedConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// url.Error repeats the URL, credentials included.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, InvalidURL(err.Error())
+	}
+
+	parser, ok := urlParsers[u.Scheme]
+	if !ok {
+		return nil, ErrUnknownDriver
+	}
+	return parser(u)
+}
+
+// Open returns the store described by a store URL. See ParseURL.
+func Open(rawURL string, opts ...Option) (Store, error) {
+	config, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return New(config, opts...)
+}
+
+// InvalidURL returns the error URL parsers report for a store URL they
+// cannot use, with reason saying why.
+func InvalidURL(reason string) error {
+	return &Error{Kind: ErrInvalidEndpoint, Op: "Open", Err: errors.New(reason)}
+}
@@ -0,0 +1,93 @@
+package objex_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/brian-nunez/objex"
+	"github.com/brian-nunez/objex/drivers/memory"
+)
+
+func init() {
+	// urltest://name?fail=reason builds the configtest driver's Config, or
+	// fails with InvalidURL(reason).
+	objex.RegisterURL("urltest", func(u *url.URL) (objex.NamedConfig, error) {
+		if reason := u.Query().Get("fail"); reason != "" {
+			return nil, objex.InvalidURL(reason)
+		}
+		return configTestConfig{Name: u.Host}, nil
+	})
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    objex.NamedConfig
+		wantErr error
+	}{
+		{url: "urltest://photos", want: configTestConfig{Name: "photos"}},
+		{url: "mem://photos", want: memory.Config{Bucket: "photos"}},
+		{url: "nope://photos", wantErr: objex.ErrUnknownDriver},
+		{url: "photos", wantErr: objex.ErrUnknownDriver},
+		{url: "urltest://photos?fail=no+photos", wantErr: objex.ErrInvalidEndpoint},
+		{url: "urltest://key:secret@%zz", wantErr: objex.ErrInvalidEndpoint},
+		{url: "://photos", wantErr: objex.ErrInvalidEndpoint},
+	}
+
+	for _, test := range tests {
+		config, err := objex.ParseURL(test.url)
+		if test.wantErr != nil {
+			wantErr(t, "ParseURL("+test.url+")", err, test.wantErr)
+			continue
+		}
+		if err != nil || config != test.want {
+			t.Errorf("ParseURL(%s): got %+v, %v, want %+v", test.url, config, err, test.want)
+		}
+	}
+}
+
+func TestParseURLHidesCredentials(t *testing.T) {
+	_, err := objex.ParseURL("urltest://key:secret@%zz")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("ParseURL: got %v, want an error without the password", err)
+	}
+}
+
+func TestInvalidURL(t *testing.T) {
+	_, err := objex.ParseURL("urltest://photos?fail=no+photos")
+
+	var e *objex.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("ParseURL: got %#v, want an *objex.Error", err)
+	}
+	if e.Kind != objex.ErrInvalidEndpoint || e.Op != "Open" || !strings.Contains(e.Error(), "no photos") {
+		t.Errorf("ParseURL: got %+v, want an Open INVALID_ENDPOINT error saying %q", e, "no photos")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	resetConfigTest(t)
+
+	store, err := objex.Open("urltest://photos")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if store == nil || len(configured) != 1 || configured[0].Name != "photos" {
+		t.Errorf("Open: got %v with configs %+v, want a configtest store named photos", store, configured)
+	}
+
+	store, err = objex.Open("mem://photos")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if bucket := objex.CurrentBucket(store); bucket != "photos" {
+		t.Errorf("Open: got bucket %q, want %q", bucket, "photos")
+	}
+
+	_, err = objex.Open("nope://photos")
+	wantErr(t, "Open", err, objex.ErrUnknownDriver)
+	_, err = objex.Open("urltest://photos?fail=no+photos")
+	wantErr(t, "Open", err, objex.ErrInvalidEndpoint)
+}
@@ -0,0 +1,65 @@
+package objex
+
+import (
+	"context"
+	"io"
+)
+
+// VersioningStatus is the versioning state of a bucket. S3 buckets start
+// unversioned and, once versioning has been enabled, can only be suspended.
+type VersioningStatus string
+
+const (
+	VersioningOff       VersioningStatus = ""
+	VersioningEnabled   VersioningStatus = "Enabled"
+	VersioningSuspended VersioningStatus = "Suspended"
+)
+
+// ObjectVersion is one version of an object, or a delete marker left by
+// deleting an object in a versioned bucket.
+type ObjectVersion struct {
+	Key       string
+	VersionID string
+	// IsLatest reports whether this is the current version of the key. A
+	// key whose latest version is a delete marker reads as missing.
+	IsLatest       bool
+	IsDeleteMarker bool
+	// Size and ETag are empty for delete markers.
+	Size         int64
+	ETag         string
+	LastModified string
+}
+
+// Versioner is implemented by stores that can keep earlier versions of
+// objects. With versioning enabled, every write adds a version and deleting
+// an object adds a delete marker instead of removing its data. Version IDs
+// are opaque strings chosen by the store; objects written while versioning
+// was off or suspended have the version ID "null".
+type Versioner interface {
+	// SetBucketVersioning enables or suspends versioning of bucketName, or
+	// the current bucket. Versions kept so far are not removed.
+	SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error
+	// BucketVersioning returns the versioning status of bucketName, or the
+	// current bucket.
+	BucketVersioning(ctx context.Context, bucketName string) (VersioningStatus, error)
+	// ListObjectVersions returns the versions and delete markers of the
+	// objects in bucketName, or the current bucket, whose keys start with
+	// prefix, in key order and newest first for each key.
+	ListObjectVersions(ctx context.Context, bucketName, prefix string) ([]ObjectVersion, error)
+	// OpenObjectVersion opens a specific version of an object. Missing
+	// versions fail with ErrVersionNotFound.
+	OpenObjectVersion(ctx context.Context, objectName, versionID string, opts GetOptions) (io.ReadCloser, *ObjectMetaData, error)
+	// DeleteObjectVersion permanently removes a version or delete marker.
+	// Removing the delete marker that is the latest version brings the
+	// object back.
+	DeleteObjectVersion(ctx context.Context, objectName, versionID string) error
+	// RestoreObjectVersion makes a copy of an earlier version the latest
+	// version of the object, keeping the versions in between.
+	RestoreObjectVersion(ctx context.Context, objectName, versionID string) error
+}
+
+// AsVersioner returns the Versioner behind store, looking through wrappers
+// such as the one returned by Adapt.
+func AsVersioner(store StoreContext) (Versioner, bool) {
+	return as[Versioner](store)
+}
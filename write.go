@@ -0,0 +1,46 @@
+package objex
+
+import "strings"
+
+// PutOptions describes the object written by PutObject.
+type PutOptions struct {
+	ContentType        string
+	ContentDisposition string
+	ContentEncoding    string
+	CacheControl       string
+	ContentLanguage    string
+	// UserMetadata is stored with the object (x-amz-meta-* on S3). Keys are
+	// case-insensitive and come back lower case.
+	UserMetadata map[string]string
+	// Conditions must hold for the object being replaced, or the write
+	// fails with ErrPreconditionFailed. They are not kept with the object.
+	Conditions Conditions
+}
+
+// PutOptions returns the options that write an object with the same headers
+// and user metadata as m, so updates can keep them.
+func (m *ObjectMetaData) PutOptions() PutOptions {
+	return PutOptions{
+		ContentType:        m.ContentType,
+		ContentDisposition: m.ContentDisposition,
+		ContentEncoding:    m.ContentEncoding,
+		CacheControl:       m.CacheControl,
+		ContentLanguage:    m.ContentLanguage,
+		UserMetadata:       m.UserMetadata,
+	}
+}
+
+// NormalizeUserMetadata returns a copy of metadata with lower case keys and
+// any "x-amz-meta-" prefix removed. It returns nil for an empty map.
+func NormalizeUserMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	normalized := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		key = strings.ToLower(key)
+		normalized[strings.TrimPrefix(key, "x-amz-meta-")] = value
+	}
+	return normalized
+}